@@ -0,0 +1,233 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sap/gorfc/gorfc"
+)
+
+// goTypes maps ABAP field types to Go types, as decoded by gorfc.Decode
+var goTypes = map[string]string{
+	"RFCTYPE_CHAR":    "string",
+	"RFCTYPE_STRING":  "string",
+	"RFCTYPE_NUM":     "string",
+	"RFCTYPE_BCD":     "gorfc.Decimal",
+	"RFCTYPE_DECF16":  "gorfc.Decimal",
+	"RFCTYPE_DECF34":  "gorfc.Decimal",
+	"RFCTYPE_UTCLONG": "time.Time",
+	"RFCTYPE_DATE":    "time.Time",
+	"RFCTYPE_TIME":    "time.Time",
+	"RFCTYPE_BYTE":    "[]byte",
+	"RFCTYPE_XSTRING": "[]byte",
+	"RFCTYPE_FLOAT":   "float64",
+	"RFCTYPE_INT":     "int32",
+	"RFCTYPE_INT1":    "uint8",
+	"RFCTYPE_INT2":    "int16",
+	"RFCTYPE_INT8":    "int64",
+}
+
+// generator emits Go structures and typed wrappers for function descriptions
+type generator struct {
+	buf      bytes.Buffer
+	usesTime bool
+	// structures of ABAP types, by Go type name
+	types map[string]gorfc.TypeDescription
+	// Go type names of ABAP types
+	structNames map[string]string
+	// Go wrapper names of functions
+	funcNames map[string]string
+	// package level identifiers, shared by wrappers, parameter and ABAP structures
+	names map[string]bool
+}
+
+func newGenerator() *generator {
+	return &generator{
+		types:       make(map[string]gorfc.TypeDescription),
+		structNames: make(map[string]string),
+		funcNames:   make(map[string]string),
+		names:       make(map[string]bool),
+	}
+}
+
+// generate returns the formatted Go source for the function descriptions
+func generate(packageName string, funcDescs []gorfc.FunctionDescription) ([]byte, error) {
+	g := newGenerator()
+	// wrapper names are reserved first, structures of ABAP types take the remaining names
+	for _, funcDesc := range funcDescs {
+		g.functionName(funcDesc.Name)
+	}
+	for _, funcDesc := range funcDescs {
+		g.function(funcDesc)
+	}
+	g.structures()
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by gorfc-gen. DO NOT EDIT.\n\npackage %v\n\nimport (\n\t\"context\"\n", packageName)
+	if g.usesTime {
+		fmt.Fprintf(&src, "\t\"time\"\n")
+	}
+	fmt.Fprintf(&src, "\n\t\"github.com/sap/gorfc/gorfc\"\n)\n")
+	src.Write(g.buf.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return src.Bytes(), fmt.Errorf("could not format generated code: %v", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) printf(format string, a ...interface{}) {
+	fmt.Fprintf(&g.buf, format, a...)
+}
+
+// functionName returns the Go wrapper name of the function, unique together with the <name>In and <name>Out types
+func (g *generator) functionName(abapName string) string {
+	if name, ok := g.funcNames[abapName]; ok {
+		return name
+	}
+	name := goName(abapName)
+	for i := 2; g.names[name] || g.names[name+"In"] || g.names[name+"Out"]; i++ {
+		name = fmt.Sprintf("%v%d", goName(abapName), i)
+	}
+	g.names[name], g.names[name+"In"], g.names[name+"Out"] = true, true, true
+	g.funcNames[abapName] = name
+	return name
+}
+
+func (g *generator) function(funcDesc gorfc.FunctionDescription) {
+	name := g.functionName(funcDesc.Name)
+	var in, out []gorfc.ParameterDescription
+	for _, paramDesc := range funcDesc.Parameters {
+		switch paramDesc.Direction {
+		case "RFC_IMPORT":
+			in = append(in, paramDesc)
+		case "RFC_EXPORT":
+			out = append(out, paramDesc)
+		default:
+			// CHANGING and TABLES parameters are passed and returned
+			in = append(in, paramDesc)
+			out = append(out, paramDesc)
+		}
+	}
+
+	g.printf("\n// %vIn holds the IMPORT, CHANGING and TABLES parameters of %v\n", name, funcDesc.Name)
+	g.parameters(name+"In", in, true)
+	g.printf("\n// %vOut holds the EXPORT, CHANGING and TABLES parameters of %v\n", name, funcDesc.Name)
+	g.parameters(name+"Out", out, false)
+
+	g.printf("\n// %v calls %v\n", name, funcDesc.Name)
+	g.printf("func %v(ctx context.Context, client gorfc.Client, in %vIn) (out %vOut, err error) {\n", name, name, name)
+	g.printf("\tresult, err := client.CallContext(ctx, %q, in)\n", funcDesc.Name)
+	g.printf("\tif err != nil {\n\t\treturn\n\t}\n")
+	g.printf("\terr = gorfc.Decode(result, &out)\n\treturn\n}\n")
+}
+
+func (g *generator) parameters(structName string, params []gorfc.ParameterDescription, input bool) {
+	g.printf("type %v struct {\n", structName)
+	names := make(map[string]bool)
+	for _, paramDesc := range params {
+		tag := paramDesc.Name
+		if input && (paramDesc.Optional || paramDesc.Direction == "RFC_TABLES") {
+			tag += ",omitempty"
+		}
+		comment := ""
+		if paramDesc.ParameterText != "" {
+			comment = " // " + paramDesc.ParameterText
+		}
+		g.printf("\t%v %v `rfc:%q`%v\n", uniqueName(goName(paramDesc.Name), names), g.goType(paramDesc.ParameterType, paramDesc.TypeDesc, paramDesc.Name), tag, comment)
+	}
+	g.printf("}\n")
+}
+
+// goType returns the Go type of ABAP type, registering structures for generation
+func (g *generator) goType(abapType string, typeDesc gorfc.TypeDescription, name string) string {
+	switch abapType {
+	case "RFCTYPE_STRUCTURE":
+		return g.structure(typeDesc, name)
+	case "RFCTYPE_TABLE":
+		return "[]" + g.structure(typeDesc, name)
+	}
+	goType, ok := goTypes[abapType]
+	if !ok {
+		return "interface{}"
+	}
+	if goType == "time.Time" {
+		g.usesTime = true
+	}
+	return goType
+}
+
+func (g *generator) structure(typeDesc gorfc.TypeDescription, name string) string {
+	if typeDesc.Name != "" {
+		name = typeDesc.Name
+	}
+	structName, ok := g.structNames[name]
+	if !ok {
+		structName = uniqueName(goName(name), g.names)
+		g.structNames[name] = structName
+		g.types[structName] = typeDesc
+		for _, fieldDesc := range typeDesc.Fields {
+			g.goType(fieldDesc.FieldType, fieldDesc.TypeDesc, fieldDesc.Name)
+		}
+	}
+	return structName
+}
+
+// structures emits the registered structures, sorted by name
+func (g *generator) structures() {
+	structNames := make([]string, 0, len(g.types))
+	for structName := range g.types {
+		structNames = append(structNames, structName)
+	}
+	sort.Strings(structNames)
+
+	for _, structName := range structNames {
+		typeDesc := g.types[structName]
+		g.printf("\n// %v is the ABAP structure %v\n", structName, typeDesc.Name)
+		g.printf("type %v struct {\n", structName)
+		names := make(map[string]bool)
+		for _, fieldDesc := range typeDesc.Fields {
+			g.printf("\t%v %v `rfc:%q`\n", uniqueName(goName(fieldDesc.Name), names), g.goType(fieldDesc.FieldType, fieldDesc.TypeDesc, fieldDesc.Name), fieldDesc.Name)
+		}
+		g.printf("}\n")
+	}
+}
+
+// goName converts ABAP name to exported Go name, like BAPI_USER_GET_DETAIL to BapiUserGetDetail
+func goName(abapName string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range abapName {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			name.WriteRune(unicode.ToUpper(r))
+		} else {
+			name.WriteRune(unicode.ToLower(r))
+		}
+		upper = false
+	}
+	if name.Len() == 0 || !unicode.IsLetter([]rune(name.String())[0]) {
+		return "X" + name.String()
+	}
+	return name.String()
+}
+
+func uniqueName(name string, names map[string]bool) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = fmt.Sprintf("%v%d", name, i)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sap/gorfc/gorfc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoName(t *testing.T) {
+	fmt.Println("Generator: Go names")
+	assert.Equal(t, "BapiUserGetDetail", goName("BAPI_USER_GET_DETAIL"))
+	assert.Equal(t, "BobfConfData", goName("/BOBF/CONF_DATA"))
+	assert.Equal(t, "X1stItem", goName("1ST_ITEM"))
+	names := make(map[string]bool)
+	assert.Equal(t, "MatnrExt", uniqueName(goName("MATNR_EXT"), names))
+	assert.Equal(t, "MatnrExt2", uniqueName(goName("MATNR__EXT"), names))
+}
+
+func TestGenerate(t *testing.T) {
+	fmt.Println("Generator: Structures and typed wrapper")
+	address := gorfc.TypeDescription{Name: "BAPIADDR3", Fields: []gorfc.FieldDescription{
+		{Name: "FIRSTNAME", FieldType: "RFCTYPE_CHAR"},
+		{Name: "BIRTH_DT", FieldType: "RFCTYPE_DATE"},
+	}}
+	ret := gorfc.TypeDescription{Name: "BAPIRET2", Fields: []gorfc.FieldDescription{
+		{Name: "TYPE", FieldType: "RFCTYPE_CHAR"},
+		{Name: "NUMBER", FieldType: "RFCTYPE_NUM"},
+		{Name: "ROW", FieldType: "RFCTYPE_INT"},
+	}}
+	src, err := generate("rfc", []gorfc.FunctionDescription{{
+		Name: "BAPI_USER_GET_DETAIL",
+		Parameters: []gorfc.ParameterDescription{
+			{Name: "USERNAME", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_IMPORT", ParameterText: "User Name"},
+			{Name: "CACHE_RESULTS", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_IMPORT", Optional: true},
+			{Name: "ADDRESS", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_EXPORT", TypeDesc: address},
+			{Name: "RETURN", ParameterType: "RFCTYPE_TABLE", Direction: "RFC_TABLES", TypeDesc: ret},
+		},
+	}})
+	assert.Nil(t, err)
+	code := string(src)
+	assert.Contains(t, code, "// Code generated by gorfc-gen. DO NOT EDIT.")
+	assert.Contains(t, code, "package rfc")
+	assert.Contains(t, code, "\"time\"")
+	assert.Contains(t, code, "Username     string     `rfc:\"USERNAME\"` // User Name")
+	assert.Contains(t, code, "CacheResults string     `rfc:\"CACHE_RESULTS,omitempty\"`")
+	assert.Contains(t, code, "Return       []Bapiret2 `rfc:\"RETURN,omitempty\"`")
+	assert.Contains(t, code, "Address Bapiaddr3  `rfc:\"ADDRESS\"`")
+	assert.Contains(t, code, "BirthDt   time.Time `rfc:\"BIRTH_DT\"`")
+	assert.Contains(t, code, "Row    int32  `rfc:\"ROW\"`")
+	assert.Contains(t, code, "func BapiUserGetDetail(ctx context.Context, client gorfc.Client, in BapiUserGetDetailIn) (out BapiUserGetDetailOut, err error) {")
+	assert.Contains(t, code, "client.CallContext(ctx, \"BAPI_USER_GET_DETAIL\", in)")
+}
+
+func TestGenerateNames(t *testing.T) {
+	fmt.Println("Generator: Unique type and wrapper names")
+	in := gorfc.TypeDescription{Name: "Z_READ_IN", Fields: []gorfc.FieldDescription{{Name: "ID", FieldType: "RFCTYPE_CHAR"}}}
+	read := gorfc.TypeDescription{Name: "ZREAD", Fields: []gorfc.FieldDescription{{Name: "ID", FieldType: "RFCTYPE_NUM"}}}
+	src, err := generate("rfc", []gorfc.FunctionDescription{
+		{Name: "Z_READ", Parameters: []gorfc.ParameterDescription{
+			{Name: "IS_IN", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_IMPORT", TypeDesc: in},
+			{Name: "ES_READ", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_EXPORT", TypeDesc: read},
+		}},
+		{Name: "ZREAD"},
+	})
+	assert.Nil(t, err)
+	code := string(src)
+	assert.Contains(t, code, "func ZRead(ctx context.Context, client gorfc.Client, in ZReadIn) (out ZReadOut, err error) {")
+	assert.Contains(t, code, "func Zread(ctx context.Context, client gorfc.Client, in ZreadIn) (out ZreadOut, err error) {")
+	assert.Contains(t, code, "IsIn ZReadIn2 `rfc:\"IS_IN\"`")
+	assert.Contains(t, code, "EsRead Zread2 `rfc:\"ES_READ\"`")
+	assert.Contains(t, code, "type ZReadIn2 struct {")
+	assert.Contains(t, code, "type Zread2 struct {")
+}
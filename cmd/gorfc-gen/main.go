@@ -0,0 +1,101 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+// Command gorfc-gen generates Go structures and typed wrappers for ABAP function modules.
+//
+// Function descriptions are read from the ABAP system of the destination in sapnwrfc.ini:
+//
+//	gorfc-gen -dest MME -package rfc -o bapi_user.go BAPI_USER_GET_DETAIL
+//
+// or from the JSON file exported by Connection.ExportFunctionDescriptions:
+//
+//	gorfc-gen -metadata metadata.json -package rfc -o rfc.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sap/gorfc/gorfc"
+)
+
+func main() {
+	dest := flag.String("dest", "", "destination in sapnwrfc.ini, to read function descriptions from ABAP system")
+	metadata := flag.String("metadata", "", "JSON file with exported function descriptions")
+	packageName := flag.String("package", "rfc", "package name of generated code")
+	output := flag.String("o", "", "output file, standard output if not set")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: gorfc-gen (-dest DEST | -metadata FILE) [-package NAME] [-o FILE] [FUNCTION ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if (*dest == "") == (*metadata == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	funcDescs, err := functionDescriptions(*dest, *metadata, flag.Args())
+	if err == nil {
+		err = write(*output, *packageName, funcDescs)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gorfc-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// functionDescriptions reads the function descriptions from the ABAP system or metadata file.
+// All functions of the metadata file are returned when no function names are given.
+func functionDescriptions(dest string, metadata string, goFuncNames []string) (funcDescs []gorfc.FunctionDescription, err error) {
+	if metadata != "" {
+		funcDescs, err = gorfc.ReadFunctionDescriptions(metadata)
+		if err != nil || len(goFuncNames) == 0 {
+			return
+		}
+		byName := make(map[string]gorfc.FunctionDescription, len(funcDescs))
+		for _, funcDesc := range funcDescs {
+			byName[funcDesc.Name] = funcDesc
+		}
+		funcDescs = nil
+		for _, goFuncName := range goFuncNames {
+			funcDesc, ok := byName[goFuncName]
+			if !ok {
+				return nil, fmt.Errorf("function %v not found in %v", goFuncName, metadata)
+			}
+			funcDescs = append(funcDescs, funcDesc)
+		}
+		return
+	}
+
+	if len(goFuncNames) == 0 {
+		return nil, fmt.Errorf("function names required with -dest")
+	}
+	conn, err := gorfc.ConnectionFromDest(dest)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for _, goFuncName := range goFuncNames {
+		var funcDesc gorfc.FunctionDescription
+		funcDesc, err = conn.GetFunctionDescription(goFuncName)
+		if err != nil {
+			return
+		}
+		funcDescs = append(funcDescs, funcDesc)
+	}
+	return
+}
+
+func write(output string, packageName string, funcDescs []gorfc.FunctionDescription) (err error) {
+	src, err := generate(packageName, funcDescs)
+	if err != nil {
+		return
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return
+	}
+	return os.WriteFile(output, src, 0644)
+}
@@ -0,0 +1,138 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sap/gorfc/gorfc"
+)
+
+// fromJSON converts the JSON parameters to Go values expected by gorfc, using the function description
+func fromJSON(funcDesc gorfc.FunctionDescription, jsonParams map[string]interface{}) (params map[string]interface{}, err error) {
+	params = make(map[string]interface{}, len(jsonParams))
+	for name, value := range jsonParams {
+		params[name] = value
+		for _, paramDesc := range funcDesc.Parameters {
+			if paramDesc.Name == name {
+				params[name], err = fromJSONValue(value, paramDesc.ParameterType, paramDesc.TypeDesc, name)
+				if err != nil {
+					return
+				}
+				break
+			}
+		}
+	}
+	return
+}
+
+func fromJSONValue(value interface{}, rfcType string, typeDesc gorfc.TypeDescription, path string) (result interface{}, err error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch rfcType {
+	case "RFCTYPE_STRUCTURE":
+		return fromJSONStructure(value, typeDesc, path)
+	case "RFCTYPE_TABLE":
+		lines, ok := value.([]interface{})
+		if !ok {
+			return nil, jsonError(value, rfcType, path)
+		}
+		table := make([]interface{}, len(lines))
+		for i, line := range lines {
+			table[i], err = fromJSONStructure(line, typeDesc, fmt.Sprintf("%v[%d]", path, i))
+			if err != nil {
+				return
+			}
+		}
+		return table, nil
+	case "RFCTYPE_INT1", "RFCTYPE_INT2", "RFCTYPE_INT", "RFCTYPE_INT8":
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, jsonError(value, rfcType, path)
+		}
+		i, err := number.Int64()
+		if err != nil {
+			return nil, jsonError(value, rfcType, path)
+		}
+		if rfcType == "RFCTYPE_INT1" {
+			return int(i), nil
+		}
+		return i, nil
+	case "RFCTYPE_FLOAT":
+		if number, ok := value.(json.Number); ok {
+			return number.Float64()
+		}
+	case "RFCTYPE_BCD", "RFCTYPE_DECF16", "RFCTYPE_DECF34":
+		// validated against the field length and decimals before the call
+		d, err := gorfc.ParseDecimal(fmt.Sprint(value))
+		if err != nil {
+			return nil, jsonError(value, rfcType, path)
+		}
+		return d, nil
+	case "RFCTYPE_DATE":
+		return parseTime(value, rfcType, path, "20060102", "2006-01-02")
+	case "RFCTYPE_TIME":
+		return parseTime(value, rfcType, path, "150405", "15:04:05")
+	case "RFCTYPE_BYTE", "RFCTYPE_XSTRING":
+		s, ok := value.(string)
+		if !ok {
+			return nil, jsonError(value, rfcType, path)
+		}
+		return base64.StdEncoding.DecodeString(s)
+	}
+
+	// character-like types are passed as strings
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return nil, jsonError(value, rfcType, path)
+}
+
+func fromJSONStructure(value interface{}, typeDesc gorfc.TypeDescription, path string) (result interface{}, err error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, jsonError(value, "RFCTYPE_STRUCTURE", path)
+	}
+	structure := make(map[string]interface{}, len(fields))
+	for name, fieldValue := range fields {
+		structure[name] = fieldValue
+		for _, fieldDesc := range typeDesc.Fields {
+			if fieldDesc.Name == name {
+				structure[name], err = fromJSONValue(fieldValue, fieldDesc.FieldType, fieldDesc.TypeDesc, path+"."+name)
+				if err != nil {
+					return
+				}
+				break
+			}
+		}
+	}
+	return structure, nil
+}
+
+func parseTime(value interface{}, rfcType string, path string, layouts ...string) (result interface{}, err error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, jsonError(value, rfcType, path)
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return nil, jsonError(value, rfcType, path)
+}
+
+func jsonError(value interface{}, rfcType string, path string) error {
+	return fmt.Errorf("JSON value %v of %v not valid for %v", value, path, strings.TrimPrefix(rfcType, "RFCTYPE_"))
+}
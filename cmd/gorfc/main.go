@@ -0,0 +1,236 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+// Command gorfc calls ABAP function modules and inspects ABAP system metadata.
+//
+// Usage:
+//
+//	gorfc [flags] ping
+//	gorfc [flags] attrs
+//	gorfc [flags] describe FUNCTION
+//	gorfc [flags] call FUNCTION < params.json
+//	gorfc [flags] dests
+//
+// Connection parameters are read from GORFC_<NAME> environment variables, like GORFC_ASHOST,
+// overridden by -p name=value flags. With -dest, the destination is read from sapnwrfc.ini,
+// in the current directory or in the directory set by -ini.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sap/gorfc/gorfc"
+)
+
+// envPrefix of environment variables with connection parameters
+const envPrefix = "GORFC_"
+
+// paramFlags collects the -p name=value flags
+type paramFlags map[string]string
+
+func (params paramFlags) String() string {
+	return fmt.Sprint(map[string]string(params))
+}
+
+func (params paramFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i < 1 {
+		return fmt.Errorf("connection parameter %q not in name=value form", value)
+	}
+	params[strings.ToLower(value[:i])] = value[i+1:]
+	return nil
+}
+
+func main() {
+	params := make(paramFlags)
+	dest := flag.String("dest", "", "destination in sapnwrfc.ini")
+	iniDir := flag.String("ini", "", "directory of sapnwrfc.ini, current directory if not set")
+	asJSON := flag.Bool("json", false, "print describe output as JSON")
+	flag.Var(params, "p", "connection parameter as name=value, can be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: gorfc [flags] ping | attrs | describe FUNCTION | call FUNCTION | dests\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 || (args[0] != "ping" && args[0] != "attrs" && args[0] != "dests" && len(args) != 2) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *iniDir != "" {
+		if err := gorfc.SetIniPath(*iniDir); err != nil {
+			exit(err)
+		}
+	}
+	if args[0] == "dests" {
+		if err := printDestinations(os.Stdout); err != nil {
+			exit(err)
+		}
+		return
+	}
+
+	conn, err := gorfc.ConnectionFromParams(connectionParameters(os.Environ(), *dest, params))
+	if err != nil {
+		exit(err)
+	}
+	defer conn.Close()
+
+	switch args[0] {
+	case "ping":
+		err = conn.Ping()
+		if err == nil {
+			fmt.Println("OK")
+		}
+	case "attrs":
+		var connAttr gorfc.ConnectionAttributes
+		connAttr, err = conn.GetConnectionAttributes()
+		if err == nil {
+			err = printAttributes(os.Stdout, connAttr)
+		}
+	case "describe":
+		var funcDesc gorfc.FunctionDescription
+		funcDesc, err = conn.GetFunctionDescription(args[1])
+		if err == nil && *asJSON {
+			err = printJSON(os.Stdout, funcDesc)
+		} else if err == nil {
+			err = printDescription(os.Stdout, funcDesc)
+		}
+	case "call":
+		err = call(conn, args[1], os.Stdin, os.Stdout)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		conn.Close()
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "gorfc:", err)
+	os.Exit(1)
+}
+
+// connectionParameters merges the GORFC_ environment variables, destination and flags
+func connectionParameters(environ []string, dest string, params paramFlags) gorfc.ConnectionParameters {
+	connParams := make(gorfc.ConnectionParameters)
+	for _, env := range environ {
+		i := strings.Index(env, "=")
+		if i > len(envPrefix) && strings.HasPrefix(env, envPrefix) {
+			connParams[strings.ToLower(env[len(envPrefix):i])] = env[i+1:]
+		}
+	}
+	if dest != "" {
+		connParams["dest"] = dest
+	}
+	for name, value := range params {
+		connParams[name] = value
+	}
+	return connParams
+}
+
+// call reads the JSON parameters, calls the function and writes the JSON result
+func call(conn *gorfc.Connection, goFuncName string, in io.Reader, out io.Writer) (err error) {
+	funcDesc, err := conn.GetFunctionDescription(goFuncName)
+	if err != nil {
+		return
+	}
+
+	decoder := json.NewDecoder(in)
+	decoder.UseNumber()
+	var jsonParams map[string]interface{}
+	err = decoder.Decode(&jsonParams)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("could not read JSON parameters: %v", err)
+	}
+
+	params, err := fromJSON(funcDesc, jsonParams)
+	if err != nil {
+		return
+	}
+	result, err := conn.Call(goFuncName, params)
+	if err != nil {
+		return
+	}
+	return printJSON(out, result)
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printDestinations prints the sapnwrfc.ini destinations with their host
+func printDestinations(out io.Writer) error {
+	destinations, err := gorfc.ReadDestinations()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, dest := range destinations.Names() {
+		params := destinations[dest]
+		host, system := params["ashost"], params["sysnr"]
+		if host == "" {
+			host, system = params["mshost"], params["sysid"]
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", dest, host, system, params["client"])
+	}
+	return w.Flush()
+}
+
+func printAttributes(out io.Writer, connAttr gorfc.ConnectionAttributes) error {
+	names := make([]string, 0, len(connAttr))
+	for name := range connAttr {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "%v\t%v\n", name, connAttr[name])
+	}
+	return w.Flush()
+}
+
+// printDescription prints the parameters and their fields as table
+func printDescription(out io.Writer, funcDesc gorfc.FunctionDescription) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%v\n", funcDesc.Name)
+	fmt.Fprintf(w, "NAME\tDIRECTION\tTYPE\tLENGTH\tDECIMALS\tOPTIONAL\tTEXT\n")
+	for _, paramDesc := range funcDesc.Parameters {
+		optional := ""
+		if paramDesc.Optional {
+			optional = "X"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", paramDesc.Name, strings.TrimPrefix(paramDesc.Direction, "RFC_"),
+			abapType(paramDesc.ParameterType, paramDesc.TypeDesc), paramDesc.NucLength, paramDesc.Decimals, optional, paramDesc.ParameterText)
+		printFields(w, paramDesc.TypeDesc, "  ")
+	}
+	return w.Flush()
+}
+
+func printFields(w io.Writer, typeDesc gorfc.TypeDescription, indent string) {
+	for _, fieldDesc := range typeDesc.Fields {
+		fmt.Fprintf(w, "%v%v\t\t%v\t%v\t%v\t\t\n", indent, fieldDesc.Name, abapType(fieldDesc.FieldType, fieldDesc.TypeDesc), fieldDesc.NucLength, fieldDesc.Decimals)
+		printFields(w, fieldDesc.TypeDesc, indent+"  ")
+	}
+}
+
+func abapType(rfcType string, typeDesc gorfc.TypeDescription) string {
+	name := strings.TrimPrefix(rfcType, "RFCTYPE_")
+	if typeDesc.Name != "" {
+		name += " " + typeDesc.Name
+	}
+	return name
+}
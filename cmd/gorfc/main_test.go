@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sap/gorfc/gorfc"
+	"github.com/stretchr/testify/assert"
+)
+
+var stfcStructure = gorfc.FunctionDescription{
+	Name: "STFC_STRUCTURE",
+	Parameters: []gorfc.ParameterDescription{
+		{Name: "IMPORTSTRUCT", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_IMPORT", NucLength: 144,
+			TypeDesc: gorfc.TypeDescription{Name: "RFCTEST", Fields: []gorfc.FieldDescription{
+				{Name: "RFCINT1", FieldType: "RFCTYPE_INT1", NucLength: 1},
+				{Name: "RFCINT4", FieldType: "RFCTYPE_INT", NucLength: 4},
+				{Name: "RFCFLOAT", FieldType: "RFCTYPE_FLOAT", NucLength: 8},
+				{Name: "RFCDATE", FieldType: "RFCTYPE_DATE", NucLength: 8},
+				{Name: "RFCTIME", FieldType: "RFCTYPE_TIME", NucLength: 6},
+				{Name: "RFCHEX3", FieldType: "RFCTYPE_BYTE", NucLength: 3},
+				{Name: "RFCCHAR4", FieldType: "RFCTYPE_CHAR", NucLength: 4},
+				{Name: "RFCDEC", FieldType: "RFCTYPE_BCD", NucLength: 8, Decimals: 2},
+			}}},
+		{Name: "RFCTABLE", ParameterType: "RFCTYPE_TABLE", Direction: "RFC_TABLES", Optional: true, ParameterText: "Test table",
+			TypeDesc: gorfc.TypeDescription{Name: "RFCTEST", Fields: []gorfc.FieldDescription{
+				{Name: "RFCCHAR4", FieldType: "RFCTYPE_CHAR", NucLength: 4},
+			}}},
+	},
+}
+
+func TestConnectionParameters(t *testing.T) {
+	fmt.Println("CLI: Connection parameters")
+	params := make(paramFlags)
+	assert.Nil(t, params.Set("USER=demo"))
+	assert.Nil(t, params.Set("passwd=a=b"))
+	assert.NotNil(t, params.Set("client"))
+
+	environ := []string{"GORFC_ASHOST=10.0.0.1", "GORFC_USER=other", "HOME=/root", "GORFC_=x"}
+	assert.Equal(t, gorfc.ConnectionParameters{
+		"ashost": "10.0.0.1",
+		"user":   "demo",
+		"passwd": "a=b",
+		"dest":   "MME",
+	}, connectionParameters(environ, "MME", params))
+}
+
+func TestFromJSON(t *testing.T) {
+	fmt.Println("CLI: JSON parameters")
+	decoder := json.NewDecoder(strings.NewReader(`{
+		"IMPORTSTRUCT": {"RFCINT1": 254, "RFCINT4": -5, "RFCFLOAT": 1.5, "RFCDATE": "2024-02-29", "RFCTIME": "235959", "RFCHEX3": "/v8A", "RFCCHAR4": "ABCD", "RFCDEC": 12345678901.23},
+		"RFCTABLE": [{"RFCCHAR4": "EFGH"}]
+	}`))
+	decoder.UseNumber()
+	var jsonParams map[string]interface{}
+	assert.Nil(t, decoder.Decode(&jsonParams))
+
+	params, err := fromJSON(stfcStructure, jsonParams)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{
+			"RFCINT1":  254,
+			"RFCINT4":  int64(-5),
+			"RFCFLOAT": 1.5,
+			"RFCDATE":  time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			"RFCTIME":  time.Date(0, 1, 1, 23, 59, 59, 0, time.UTC),
+			"RFCHEX3":  []byte{0xfe, 0xff, 0},
+			"RFCCHAR4": "ABCD",
+			"RFCDEC":   gorfc.NewDecimal(1234567890123, 2),
+		},
+		"RFCTABLE": []interface{}{map[string]interface{}{"RFCCHAR4": "EFGH"}},
+	}, params)
+
+	_, err = fromJSON(stfcStructure, map[string]interface{}{"IMPORTSTRUCT": map[string]interface{}{"RFCDATE": "29.02.2024"}})
+	assert.Equal(t, "JSON value 29.02.2024 of IMPORTSTRUCT.RFCDATE not valid for DATE", err.Error())
+	_, err = fromJSON(stfcStructure, map[string]interface{}{"IMPORTSTRUCT": map[string]interface{}{"RFCDEC": true}})
+	assert.Equal(t, "JSON value true of IMPORTSTRUCT.RFCDEC not valid for BCD", err.Error())
+	_, err = fromJSON(stfcStructure, map[string]interface{}{"RFCTABLE": map[string]interface{}{}})
+	assert.NotNil(t, err)
+}
+
+func TestPrintDescription(t *testing.T) {
+	fmt.Println("CLI: Describe function as table")
+	var out bytes.Buffer
+	assert.Nil(t, printDescription(&out, stfcStructure))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, "STFC_STRUCTURE", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "NAME"))
+	assert.Contains(t, lines[2], "IMPORTSTRUCT")
+	assert.Contains(t, lines[2], "STRUCTURE RFCTEST")
+	assert.True(t, strings.HasPrefix(lines[3], "  RFCINT1"))
+	assert.Contains(t, out.String(), "Test table")
+}
@@ -0,0 +1,320 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+//################################################################################
+//# METADATA CACHE                                                               #
+//################################################################################
+//# Function descriptions are cached by ABAP system ID, logon language and function
+//# name, shared by all connections of the process. The native descriptions are owned
+//# by the SAP NWRFC SDK repository of the ABAP system, invalidation removes them from
+//# there too. Calls count the function descriptions of the system as in use, the
+//# invalidation waits for the running calls and new calls wait for the invalidation.
+
+type functionKey struct {
+	sysID    string
+	language string
+	funcName string
+}
+
+type cachedFunction struct {
+	handle C.RFC_FUNCTION_DESC_HANDLE
+	desc   FunctionDescription
+}
+
+var metadataCache = struct {
+	sync.Mutex
+	functions map[functionKey]cachedFunction
+	// inUse counts the calls using function descriptions of the system
+	inUse map[string]int
+	// removing counts the invalidations of the system, all systems with empty sysID
+	removing map[string]int
+	changed  *sync.Cond
+}{
+	functions: make(map[functionKey]cachedFunction),
+	inUse:     make(map[string]int),
+	removing:  make(map[string]int),
+}
+
+func init() {
+	metadataCache.changed = sync.NewCond(&metadataCache.Mutex)
+}
+
+// acquireRepository counts the function descriptions of the system as in use, after running invalidations
+func acquireRepository(sysID string) {
+	metadataCache.Lock()
+	for metadataCache.removing[sysID] > 0 || metadataCache.removing[""] > 0 {
+		metadataCache.changed.Wait()
+	}
+	metadataCache.inUse[sysID]++
+	metadataCache.Unlock()
+}
+
+// releaseRepository ends the use counted by acquireRepository
+func releaseRepository(sysID string) {
+	metadataCache.Lock()
+	if metadataCache.inUse[sysID]--; metadataCache.inUse[sysID] == 0 {
+		delete(metadataCache.inUse, sysID)
+		metadataCache.changed.Broadcast()
+	}
+	metadataCache.Unlock()
+}
+
+// removeDescriptions runs remove when no call uses function descriptions of the system, all systems with empty sysID.
+// New calls wait until remove returned.
+func removeDescriptions(sysID string, remove func() error) error {
+	metadataCache.Lock()
+	defer metadataCache.Unlock()
+	metadataCache.removing[sysID]++
+	defer func() {
+		if metadataCache.removing[sysID]--; metadataCache.removing[sysID] == 0 {
+			delete(metadataCache.removing, sysID)
+		}
+		metadataCache.changed.Broadcast()
+	}()
+	for metadataCache.inUse[sysID] > 0 || (sysID == "" && len(metadataCache.inUse) > 0) {
+		metadataCache.changed.Wait()
+	}
+	return remove()
+}
+
+// getSysID returns the ABAP system ID and the logon language of the connection, the metadata cache key
+func (conn *Connection) getSysID() (sysID string, language string, err error) {
+	if conn.sysID != "" {
+		return conn.sysID, conn.language, nil
+	}
+	attributes, err := conn.getConnectionAttributes()
+	if err != nil {
+		return
+	}
+	conn.sysID = attributes["sysId"]
+	conn.language = attributes["language"]
+	return conn.sysID, conn.language, nil
+}
+
+// lookupFunction returns the cached function description of the logon language, or the one
+// loaded by LoadFunctionDescriptions for all languages. With empty language any language is found.
+func lookupFunction(sysID string, language string, goFuncName string) (cached cachedFunction, ok bool) {
+	metadataCache.Lock()
+	defer metadataCache.Unlock()
+	if cached, ok = metadataCache.functions[functionKey{sysID, language, goFuncName}]; ok {
+		return
+	}
+	if cached, ok = metadataCache.functions[functionKey{sysID, "", goFuncName}]; ok || language != "" {
+		return
+	}
+	for key, cached := range metadataCache.functions {
+		if key.sysID == sysID && key.funcName == goFuncName {
+			return cached, true
+		}
+	}
+	return
+}
+
+// getFunctionDesc returns the cached function description, read from the ABAP system if not cached.
+// The returned handle is in use until releaseFunctionDesc.
+func (conn *Connection) getFunctionDesc(goFuncName string) (handle C.RFC_FUNCTION_DESC_HANDLE, desc FunctionDescription, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	sysID, language, err := conn.getSysID()
+	if err != nil {
+		return
+	}
+	acquireRepository(sysID)
+	defer func() {
+		if err != nil {
+			releaseRepository(sysID)
+		}
+	}()
+	if cached, ok := lookupFunction(sysID, language, goFuncName); ok {
+		return cached.handle, cached.desc, nil
+	}
+
+	funcName, err := fillString(goFuncName)
+	defer C.free(unsafe.Pointer(funcName))
+	if err != nil {
+		return
+	}
+
+	handle = C.RfcGetFunctionDesc(conn.handle, funcName, &errorInfo)
+	if handle == nil {
+		return handle, desc, rfcError(errorInfo, "Could not get function description for \"%v\"", goFuncName)
+	}
+
+	desc, err = wrapFunctionDescription(handle)
+	if err != nil {
+		return
+	}
+
+	metadataCache.Lock()
+	metadataCache.functions[functionKey{sysID, language, goFuncName}] = cachedFunction{handle, desc}
+	metadataCache.Unlock()
+	return
+}
+
+// releaseFunctionDesc ends the use of the function description returned by getFunctionDesc
+func (conn *Connection) releaseFunctionDesc() {
+	releaseRepository(conn.sysID)
+}
+
+// PrewarmMetadata reads the function descriptions into the metadata cache.
+func (conn *Connection) PrewarmMetadata(goFuncNames ...string) (err error) {
+	if err = conn.checkPinned("PrewarmMetadata"); err != nil {
+		return
+	}
+	if !conn.alive {
+		err = conn.Open()
+		if err != nil {
+			return
+		}
+	}
+	for _, goFuncName := range goFuncNames {
+		_, _, err = conn.getFunctionDesc(goFuncName)
+		if err != nil {
+			return
+		}
+		conn.releaseFunctionDesc()
+	}
+	return
+}
+
+// InvalidateFunction removes the function description of the ABAP system sysID from the metadata cache,
+// in all logon languages, and from the SAP NWRFC SDK repository, to be read again from the ABAP system.
+// Waits for the calls running with function descriptions of sysID. Functions installed by
+// Server.AddFunction must not be invalidated.
+func InvalidateFunction(sysID string, goFuncName string) (err error) {
+	return removeDescriptions(sysID, func() error {
+		for key := range metadataCache.functions {
+			if key.sysID == sysID && key.funcName == goFuncName {
+				delete(metadataCache.functions, key)
+			}
+		}
+		return removeFunctionDesc(sysID, goFuncName)
+	})
+}
+
+// InvalidateType removes the type description of the ABAP system sysID from the SAP NWRFC SDK repository,
+// together with the function descriptions using the type, in all logon languages, see InvalidateFunction.
+func InvalidateType(sysID string, goTypeName string) (err error) {
+	return removeDescriptions(sysID, func() (err error) {
+		var errorInfo C.RFC_ERROR_INFO
+
+		funcNames := make(map[string]bool)
+		for key, cached := range metadataCache.functions {
+			if key.sysID == sysID && functionUsesType(cached.desc, goTypeName) {
+				funcNames[key.funcName] = true
+				delete(metadataCache.functions, key)
+			}
+		}
+		for goFuncName := range funcNames {
+			err = removeFunctionDesc(sysID, goFuncName)
+			if err != nil {
+				return
+			}
+		}
+
+		repositoryID, err := fillString(sysID)
+		defer C.free(unsafe.Pointer(repositoryID))
+		if err != nil {
+			return
+		}
+		typeName, err := fillString(goTypeName)
+		defer C.free(unsafe.Pointer(typeName))
+		if err != nil {
+			return
+		}
+		rc := C.RfcRemoveTypeDesc(repositoryID, typeName, &errorInfo)
+		if rc != C.RFC_OK && rc != C.RFC_NOT_FOUND {
+			return rfcError(errorInfo, "Could not remove type description for \"%v\"", goTypeName)
+		}
+		return
+	})
+}
+
+// ClearMetadataCache removes all function and type descriptions of the ABAP system sysID from the metadata cache
+// and the SAP NWRFC SDK repository. With empty sysID, descriptions of all ABAP systems are removed, see InvalidateFunction.
+func ClearMetadataCache(sysID string) (err error) {
+	return removeDescriptions(sysID, func() (err error) {
+		var errorInfo C.RFC_ERROR_INFO
+
+		sysIDs := make(map[string]bool)
+		for key := range metadataCache.functions {
+			if sysID == "" || key.sysID == sysID {
+				sysIDs[key.sysID] = true
+				delete(metadataCache.functions, key)
+			}
+		}
+		if sysID != "" {
+			sysIDs[sysID] = true
+		}
+
+		for id := range sysIDs {
+			var repositoryID *C.SAP_UC
+			repositoryID, err = fillString(id)
+			if err != nil {
+				C.free(unsafe.Pointer(repositoryID))
+				return
+			}
+			rc := C.RfcClearRepository(repositoryID, &errorInfo)
+			C.free(unsafe.Pointer(repositoryID))
+			if rc != C.RFC_OK && rc != C.RFC_NOT_FOUND {
+				return rfcError(errorInfo, "Could not clear repository \"%v\"", id)
+			}
+		}
+		return
+	})
+}
+
+// removeFunctionDesc removes the function description from the SAP NWRFC SDK repository of the system
+func removeFunctionDesc(sysID string, goFuncName string) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	repositoryID, err := fillString(sysID)
+	defer C.free(unsafe.Pointer(repositoryID))
+	if err != nil {
+		return
+	}
+	funcName, err := fillString(goFuncName)
+	defer C.free(unsafe.Pointer(funcName))
+	if err != nil {
+		return
+	}
+	rc := C.RfcRemoveFunctionDesc(repositoryID, funcName, &errorInfo)
+	if rc != C.RFC_OK && rc != C.RFC_NOT_FOUND {
+		return rfcError(errorInfo, "Could not remove function description for \"%v\"", goFuncName)
+	}
+	return
+}
+
+func functionUsesType(funcDesc FunctionDescription, goTypeName string) bool {
+	for _, param := range funcDesc.Parameters {
+		if typeUsesType(param.TypeDesc, goTypeName) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeUsesType(typeDesc TypeDescription, goTypeName string) bool {
+	if typeDesc.Name == goTypeName {
+		return true
+	}
+	for _, field := range typeDesc.Fields {
+		if typeUsesType(field.TypeDesc, goTypeName) {
+			return true
+		}
+	}
+	return false
+}
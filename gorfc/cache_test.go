@@ -0,0 +1,122 @@
+package gorfc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Metadata Cache Tests
+//
+
+func TestFunctionUsesType(t *testing.T) {
+	fmt.Println("Metadata cache: function uses type")
+	funcDesc := FunctionDescription{
+		Name: "STFC_STRUCTURE",
+		Parameters: []ParameterDescription{
+			{Name: "IMPORTSTRUCT", TypeDesc: TypeDescription{Name: "RFCTEST", Fields: []FieldDescription{
+				{Name: "NESTED", TypeDesc: TypeDescription{Name: "RFCNESTED"}},
+			}}},
+		},
+	}
+	assert.True(t, functionUsesType(funcDesc, "RFCTEST"))
+	assert.True(t, functionUsesType(funcDesc, "RFCNESTED"))
+	assert.False(t, functionUsesType(funcDesc, "BAPIRET2"))
+}
+
+func TestRemoveDescriptionsWaits(t *testing.T) {
+	fmt.Println("Metadata cache: invalidation waits for running calls")
+	acquireRepository("TST")
+	removed := make(chan bool)
+	go func() {
+		assert.Nil(t, removeDescriptions("TST", func() error {
+			close(removed)
+			return nil
+		}))
+	}()
+	select {
+	case <-removed:
+		assert.Fail(t, "removed while in use")
+	case <-time.After(50 * time.Millisecond):
+	}
+	releaseRepository("TST")
+	<-removed
+
+	// all systems
+	acquireRepository("TST")
+	done := make(chan error)
+	go func() {
+		done <- removeDescriptions("", func() error { return nil })
+	}()
+	select {
+	case <-done:
+		assert.Fail(t, "removed while in use")
+	case <-time.After(50 * time.Millisecond):
+	}
+	releaseRepository("TST")
+	assert.Nil(t, <-done)
+	assert.Empty(t, metadataCache.inUse)
+	assert.Empty(t, metadataCache.removing)
+}
+
+func TestMetadataCache(t *testing.T) {
+	fmt.Println("Metadata cache: prewarm and invalidate")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	assert.Nil(t, ClearMetadataCache(""))
+
+	assert.Nil(t, c.PrewarmMetadata("STFC_CONNECTION", "STFC_STRUCTURE"))
+	sysID, language, err := c.getSysID()
+	assert.Nil(t, err)
+	assert.Contains(t, metadataCache.functions, functionKey{sysID, language, "STFC_CONNECTION"})
+	assert.Contains(t, metadataCache.functions, functionKey{sysID, language, "STFC_STRUCTURE"})
+
+	// shared with other connections
+	c2, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	d, err := c2.GetFunctionDescription("STFC_STRUCTURE")
+	assert.Nil(t, err)
+	assert.Equal(t, "STFC_STRUCTURE", d.Name)
+
+	assert.Nil(t, InvalidateFunction(sysID, "STFC_CONNECTION"))
+	assert.NotContains(t, metadataCache.functions, functionKey{sysID, language, "STFC_CONNECTION"})
+
+	assert.Nil(t, InvalidateType(sysID, "RFCTEST"))
+	assert.NotContains(t, metadataCache.functions, functionKey{sysID, language, "STFC_STRUCTURE"})
+
+	// read again after invalidation
+	r, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Hällö", r["ECHOTEXT"])
+	assert.Contains(t, metadataCache.functions, functionKey{sysID, language, "STFC_CONNECTION"})
+
+	// loaded descriptions are used in all logon languages
+	d, err = c.GetFunctionDescription("STFC_CONNECTION")
+	assert.Nil(t, err)
+	assert.Nil(t, LoadFunctionDescriptions(sysID, d))
+	assert.NotContains(t, metadataCache.functions, functionKey{sysID, language, "STFC_CONNECTION"})
+	assert.Contains(t, metadataCache.functions, functionKey{sysID, "", "STFC_CONNECTION"})
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"})
+	assert.Nil(t, err)
+	assert.NotContains(t, metadataCache.functions, functionKey{sysID, language, "STFC_CONNECTION"})
+
+	// invalidated descriptions are read again from the ABAP system, not from the SAP NWRFC SDK repository
+	changed := d
+	changed.Parameters = append([]ParameterDescription{}, d.Parameters[:1]...)
+	assert.Nil(t, LoadFunctionDescriptions(sysID, changed))
+	loaded, err := c.GetFunctionDescription("STFC_CONNECTION")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(loaded.Parameters))
+	assert.Nil(t, InvalidateFunction(sysID, "STFC_CONNECTION"))
+	read, err := c.GetFunctionDescription("STFC_CONNECTION")
+	assert.Nil(t, err)
+	assert.Equal(t, d, read)
+
+	assert.Nil(t, ClearMetadataCache(sysID))
+	assert.Empty(t, metadataCache.functions)
+	c.Close()
+	c2.Close()
+}
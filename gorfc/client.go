@@ -0,0 +1,121 @@
+package gorfc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+//################################################################################
+//# CLIENT                                                                       #
+//################################################################################
+//# Client interface, function descriptions and parameter helpers in pure Go,
+//# shared by the Connection and the FakeConnection
+
+// Client is the interface of the client connection, implemented by Connection and,
+// for tests without ABAP system, by FakeConnection.
+type Client interface {
+	Call(goFuncName string, params interface{}) (result map[string]interface{}, err error)
+	CallContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error)
+	Ping() error
+	GetFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error)
+	GetConnectionAttributes() (connAttr ConnectionAttributes, err error)
+	Close() error
+}
+
+// ConnectionAttributes returned by getConnectionInfo() method
+type ConnectionAttributes map[string]string
+
+// FieldDescription type
+type FieldDescription struct {
+	Name      string          `json:"name"`
+	FieldType string          `json:"fieldType"`
+	NucLength uint            `json:"nucLength"`
+	NucOffset uint            `json:"nucOffset"`
+	UcLength  uint            `json:"ucLength"`
+	UcOffset  uint            `json:"ucOffset"`
+	Decimals  uint            `json:"decimals"`
+	TypeDesc  TypeDescription `json:"typeDesc"`
+}
+
+// TypeDescription type
+type TypeDescription struct {
+	Name      string             `json:"name"`
+	NucLength uint               `json:"nucLength"`
+	UcLength  uint               `json:"ucLength"`
+	Fields    []FieldDescription `json:"fields"`
+}
+
+// ParameterDescription type
+type ParameterDescription struct {
+	Name          string          `json:"name"`
+	ParameterType string          `json:"parameterType"`
+	Direction     string          `json:"direction"`
+	NucLength     uint            `json:"nucLength"`
+	UcLength      uint            `json:"ucLength"`
+	Decimals      uint            `json:"decimals"`
+	DefaultValue  string          `json:"defaultValue"`
+	ParameterText string          `json:"parameterText"`
+	Optional      bool            `json:"optional"`
+	TypeDesc      TypeDescription `json:"typeDesc"`
+	// ExtendedDescription interface{} //This field can be used by the application programmer (i.e. you) to store arbitrary extra information.
+}
+
+func (paramDesc ParameterDescription) String() string {
+	return fmt.Sprintf("paramDesc(name= %v, paramType= %v, dir= %v, nucLen= %v, ucLen= %v, dec= %v, defValue= %v, paramText= %v, optional= %v, typeDesc= %v)",
+		paramDesc.Name, paramDesc.ParameterType, paramDesc.Direction, paramDesc.NucLength, paramDesc.UcLength, paramDesc.Decimals, paramDesc.DefaultValue, paramDesc.ParameterText, paramDesc.Optional, paramDesc.TypeDesc)
+}
+
+// FunctionDescription type
+type FunctionDescription struct {
+	Name       string                 `json:"name"`
+	Parameters []ParameterDescription `json:"parameters"`
+}
+
+func (funcDesc FunctionDescription) String() (result string) {
+	result = fmt.Sprintf("FunctionDescription:\n Name: %v\n Parameters:\n", funcDesc.Name)
+	for i := 0; i < len(funcDesc.Parameters); i++ {
+		result += fmt.Sprintf("    %v\n", funcDesc.Parameters[i])
+	}
+	return
+}
+
+// forEachParameter calls fn for each parameter passed as map or Go structure
+func forEachParameter(params interface{}, fn func(goName string, value interface{}) error) (err error) {
+	paramsValue := reflect.Indirect(reflect.ValueOf(params))
+	if !paramsValue.IsValid() {
+		// no parameters
+	} else if paramsValue.Type().Kind() == reflect.Map {
+		keys := paramsValue.MapKeys()
+		if len(keys) > 0 {
+			if keys[0].Kind() == reflect.String {
+				for _, nameValue := range keys {
+					fieldName := nameValue.String()
+					fieldValue := paramsValue.MapIndex(nameValue).Interface()
+
+					err = fn(fieldName, fieldValue)
+					if err != nil {
+						return
+					}
+				}
+			} else {
+				return &RfcError{Description: "Could not fill parameters passed as map with non-string keys"}
+			}
+		}
+	} else if paramsValue.Type().Kind() == reflect.Struct {
+		for _, field := range structFields(paramsValue.Type()) {
+			fieldValue, ok := fieldByIndex(paramsValue, field.index, false)
+			if !ok || (field.omitEmpty && fieldValue.IsZero()) {
+				continue
+			}
+
+			err = fn(field.name, fieldValue.Interface())
+			if err != nil {
+				return
+			}
+		}
+	} else {
+		return &RfcError{Description: "Parameters can only be passed as types map[string]interface{} or go-structures"}
+	}
+	return
+}
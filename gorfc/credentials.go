@@ -0,0 +1,90 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+//################################################################################
+//# CREDENTIALS                                                                  #
+//################################################################################
+
+// CredentialProvider supplies secret connection parameters, like passwd, mysapsso2 or x509cert.
+// Credentials is called before the connection is opened or reopened and the returned parameters
+// override the connection parameters. They are used for opening only and not kept by the connection.
+type CredentialProvider interface {
+	Credentials() (ConnectionParameters, error)
+}
+
+// CredentialFunc is the CredentialProvider calling the function
+type CredentialFunc func() (ConnectionParameters, error)
+
+// Credentials returns f()
+func (f CredentialFunc) Credentials() (ConnectionParameters, error) {
+	return f()
+}
+
+// EnvCredentials returns the CredentialProvider reading connection parameters from environment variables,
+// given by connection parameter name, like {"passwd": "SAP_PASSWORD"}. Variables are read on each call.
+func EnvCredentials(variables map[string]string) CredentialProvider {
+	return CredentialFunc(func() (credentials ConnectionParameters, err error) {
+		credentials = make(ConnectionParameters, len(variables))
+		for name, variable := range variables {
+			value, ok := os.LookupEnv(variable)
+			if !ok || value == "" {
+				return nil, goRfcError(fmt.Sprintf("Credential %v: environment variable %v not set", name, variable), nil)
+			}
+			credentials[name] = value
+		}
+		return
+	})
+}
+
+// FileCredentials returns the CredentialProvider reading connection parameters from files, given by
+// connection parameter name, like {"passwd": "/run/secrets/sap-password"}. Files are read on each call,
+// the trailing newline is removed.
+func FileCredentials(files map[string]string) CredentialProvider {
+	return CredentialFunc(func() (credentials ConnectionParameters, err error) {
+		credentials = make(ConnectionParameters, len(files))
+		for name, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, goRfcError(fmt.Sprintf("Credential %v could not be read", name), err)
+			}
+			credentials[name] = strings.TrimRight(string(content), "\r\n")
+		}
+		return
+	})
+}
+
+// credentials returns the connection parameters merged with the provider credentials
+func (conn *Connection) credentials() (params ConnectionParameters, err error) {
+	credentials, err := conn.credentialProvider.Credentials()
+	if err != nil {
+		if _, ok := err.(*GoRfcError); !ok {
+			err = goRfcError("Credentials could not be provided", err)
+		}
+		return
+	}
+	provided := make(map[string]bool, len(credentials))
+	for name := range credentials {
+		if !validParams[strings.ToLower(name)] {
+			return nil, goRfcError(fmt.Sprintf("Credential %v is not a connection parameter", name), nil)
+		}
+		provided[strings.ToLower(name)] = true
+	}
+	params = make(ConnectionParameters, len(conn.connectionParams)+len(credentials))
+	for name, value := range conn.connectionParams {
+		if !provided[strings.ToLower(name)] {
+			params[name] = value
+		}
+	}
+	for name, value := range credentials {
+		params[name] = value
+	}
+	return
+}
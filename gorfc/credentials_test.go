@@ -0,0 +1,87 @@
+package gorfc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Credentials Tests
+//
+
+func TestEnvCredentials(t *testing.T) {
+	fmt.Println("Credentials: Environment variables")
+	provider := EnvCredentials(map[string]string{"passwd": "GORFC_TEST_PASSWD"})
+	_, err := provider.Credentials()
+	assert.Equal(t, "Credential passwd: environment variable GORFC_TEST_PASSWD not set", err.(*GoRfcError).Description)
+
+	t.Setenv("GORFC_TEST_PASSWD", "welcome")
+	credentials, err := provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, ConnectionParameters{"passwd": "welcome"}, credentials)
+
+	t.Setenv("GORFC_TEST_PASSWD", "rotated")
+	credentials, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, ConnectionParameters{"passwd": "rotated"}, credentials)
+}
+
+func TestFileCredentials(t *testing.T) {
+	fmt.Println("Credentials: Files")
+	file := filepath.Join(t.TempDir(), "sap-password")
+	provider := FileCredentials(map[string]string{"passwd": file})
+	_, err := provider.Credentials()
+	assert.Equal(t, "Credential passwd could not be read", err.(*GoRfcError).Description)
+
+	assert.Nil(t, os.WriteFile(file, []byte("welcome\n"), 0600))
+	credentials, err := provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, ConnectionParameters{"passwd": "welcome"}, credentials)
+}
+
+func TestCredentialErrors(t *testing.T) {
+	fmt.Println("Credentials: Provider errors")
+	params := abapSystem()
+	delete(params, "passwd")
+
+	_, err := ConnectionWithCredentials(params, CredentialFunc(func() (ConnectionParameters, error) {
+		return nil, errors.New("vault sealed")
+	}))
+	assert.Equal(t, "Credentials could not be provided", err.(*GoRfcError).Description)
+	assert.Equal(t, "vault sealed", errors.Unwrap(err).Error())
+
+	_, err = ConnectionWithCredentials(params, CredentialFunc(func() (ConnectionParameters, error) {
+		return ConnectionParameters{"pasword": "welcome"}, nil
+	}))
+	assert.Equal(t, "Credential pasword is not a connection parameter", err.(*GoRfcError).Description)
+}
+
+func TestCredentialsRotated(t *testing.T) {
+	fmt.Println("Credentials: Rotated after logon failure")
+	params := abapSystem()
+	passwd := params["passwd"]
+	params["passwd"] = "not used"
+	calls := 0
+	provider := CredentialFunc(func() (ConnectionParameters, error) {
+		calls++
+		if calls == 1 {
+			return ConnectionParameters{"passwd": "expired"}, nil
+		}
+		return ConnectionParameters{"passwd": passwd}, nil
+	})
+	c, err := ConnectionWithCredentials(params, provider)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.True(t, c.Alive())
+	assert.NotContains(t, c.String(), passwd)
+
+	assert.Nil(t, c.Reopen())
+	assert.Equal(t, 3, calls)
+	assert.Nil(t, c.Ping())
+	c.Close()
+}
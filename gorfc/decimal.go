@@ -0,0 +1,225 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//################################################################################
+//# DECIMAL                                                                      #
+//################################################################################
+
+// Decimal is the exact decimal number of ABAP BCD (packed), DECF16 and DECF34 types,
+// the unscaled integer value divided by 10^scale. The zero value is 0.
+// Decimal values are immutable, operations return new values.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// maxDecimalScale and maxDecimalExponent bound the parsed numbers to the DECF34 range, from 1E-6176 to 9.99E+6144
+const (
+	maxDecimalScale    = 6176
+	maxDecimalExponent = 6144
+)
+
+var decimalPattern = regexp.MustCompile(`^([+-]?)([0-9]*)(?:\.([0-9]*))?(?:[eE]([+-]?[0-9]+))?([+-]?)$`)
+
+// NewDecimal returns the Decimal unscaled / 10^scale, like NewDecimal(12345, 2) for 123.45
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return Decimal{big.NewInt(unscaled), scale}
+}
+
+// ParseDecimal parses the decimal number, with optional exponent like "1.5E+3".
+// The ABAP format with trailing sign, like "123.45-", is accepted as well.
+// Numbers with more decimals or larger exponent than DECF34 are rejected.
+func ParseDecimal(s string) (d Decimal, err error) {
+	m := decimalPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || m[2]+m[3] == "" || (m[1] != "" && m[5] != "") {
+		return d, goRfcError(fmt.Sprintf("Invalid decimal number %q", s), nil)
+	}
+	d.unscaled, _ = new(big.Int).SetString(m[2]+m[3], 10)
+	d.scale = len(m[3])
+	if m[4] != "" {
+		exponent, err := strconv.Atoi(m[4])
+		if err != nil {
+			return d, goRfcError(fmt.Sprintf("Invalid decimal number %q", s), err)
+		}
+		// checked before subtracting, to not overflow the scale
+		if limit := maxDecimalScale + maxDecimalExponent + len(m[2]+m[3]); exponent > limit || exponent < -limit {
+			return d, goRfcError(fmt.Sprintf("Decimal number %q out of DECF34 range", s), nil)
+		}
+		d.scale -= exponent
+	}
+	if d.scale > maxDecimalScale || len(d.unscaled.String())-1-d.scale > maxDecimalExponent {
+		return Decimal{}, goRfcError(fmt.Sprintf("Decimal number %q out of DECF34 range", s), nil)
+	}
+	if m[1] == "-" || m[5] == "-" {
+		d.unscaled.Neg(d.unscaled)
+	}
+	return
+}
+
+// Unscaled returns the unscaled integer value
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of digits after the decimal point, negative for trailing zeros of large numbers
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1
+func (d Decimal) Sign() int {
+	if d.unscaled == nil {
+		return 0
+	}
+	return d.unscaled.Sign()
+}
+
+// Rat returns the exact rational value
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.Unscaled())
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(d.scale))), nil)
+	if d.scale >= 0 {
+		return r.Quo(r, new(big.Rat).SetInt(pow))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(pow))
+}
+
+// Float64 returns the nearest float64 value
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp compares the values, returns -1 if d < other, 0 if equal, +1 if d > other
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// String returns the number with scale digits after the decimal point, without exponent
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.Unscaled()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		if d.Sign() == 0 {
+			return "0"
+		}
+		return sign + digits + strings.Repeat("0", -d.scale)
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+}
+
+// decfloatString returns the number in exponent form if the plain form would have more digits than DECF34
+func (d Decimal) decfloatString() string {
+	unscaled, scale := d.significant()
+	if unscaled.Sign() == 0 || (scale >= 0 && scale <= 34) {
+		return d.String()
+	}
+	digits := new(big.Int).Abs(unscaled).String()
+	sign := ""
+	if unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	mantissa := digits[:1]
+	if len(digits) > 1 {
+		mantissa += "." + digits[1:]
+	}
+	return fmt.Sprintf("%v%vE%+d", sign, mantissa, len(digits)-1-scale)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Decimal) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDecimal(string(text))
+	return
+}
+
+// MarshalJSON returns the JSON number, without loss of precision
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts JSON number or string, null leaves the value unchanged
+func (d *Decimal) UnmarshalJSON(data []byte) (err error) {
+	if string(data) == "null" {
+		return
+	}
+	*d, err = ParseDecimal(strings.Trim(string(data), `"`))
+	return
+}
+
+// significant returns the unscaled value and scale without trailing zeros
+func (d Decimal) significant() (unscaled *big.Int, scale int) {
+	unscaled, scale = d.Unscaled(), d.scale
+	if unscaled.Sign() == 0 {
+		return unscaled, 0
+	}
+	ten := big.NewInt(10)
+	remainder := new(big.Int)
+	for {
+		quotient, _ := new(big.Int).QuoRem(unscaled, ten, remainder)
+		if remainder.Sign() != 0 {
+			return
+		}
+		unscaled, scale = quotient, scale-1
+	}
+}
+
+// digits returns the number of significant digits and the scale without trailing zeros
+func (d Decimal) digits() (digits int, scale int) {
+	unscaled, scale := d.significant()
+	if unscaled.Sign() != 0 {
+		digits = len(new(big.Int).Abs(unscaled).String())
+	}
+	return
+}
+
+// checkPacked returns error if the value does not fit the BCD of nucLength bytes with decimals
+func checkPacked(d Decimal, nucLength int, decimals int) error {
+	digits, scale := d.digits()
+	if scale > decimals {
+		return fmt.Errorf("decimal %v has %v decimals, %v allowed", d, scale, decimals)
+	}
+	// 2 digits per byte, the sign in the last half byte
+	if integerDigits, allowed := digits-scale, 2*nucLength-1-decimals; integerDigits > allowed {
+		return fmt.Errorf("decimal %v has %v integer digits, %v allowed", d, integerDigits, allowed)
+	}
+	return nil
+}
+
+// checkDecfloat returns error if the value has more significant digits than DECF16 or DECF34 precision
+func checkDecfloat(d Decimal, precision int) error {
+	if digits, _ := d.digits(); digits > precision {
+		return fmt.Errorf("decimal %v has %v significant digits, %v allowed", d, digits, precision)
+	}
+	return nil
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,156 @@
+package gorfc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sap/gorfc/gorfc/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Decimal Tests
+//
+
+func TestParseDecimal(t *testing.T) {
+	fmt.Println("Decimal: Parse and format")
+	for s, expected := range map[string]string{
+		"0":        "0",
+		"123.45":   "123.45",
+		"-123.45":  "-123.45",
+		"123.45-":  "-123.45",
+		" +0.001 ": "0.001",
+		".5":       "0.5",
+		"1.":       "1",
+		"1.50":     "1.50",
+		"1.5E+3":   "1500",
+		"-25E-4":   "-0.0025",
+		"12345678901234567890123456789.123456789": "12345678901234567890123456789.123456789",
+	} {
+		d, err := ParseDecimal(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, d.String(), s)
+	}
+	for _, s := range []string{"", ".", "-", "1,5", "1.5.0", "-1-", "E3", "0x10"} {
+		_, err := ParseDecimal(s)
+		assert.NotNil(t, err, s)
+	}
+
+	// DECF34 range
+	for _, s := range []string{"1E-6176", "9.999999999999999999999999999999999E+6144", "0E+6144", "-1E6144"} {
+		_, err := ParseDecimal(s)
+		assert.Nil(t, err, s)
+	}
+	for _, s := range []string{"1e2000000000", "1E-6177", "1E+6145", "10E6144", "0.1E-6176", "1E-9223372036854775808"} {
+		_, err := ParseDecimal(s)
+		assert.Equal(t, fmt.Sprintf("Decimal number %q out of DECF34 range", s), err.(*GoRfcError).Description)
+	}
+	var huge Decimal
+	assert.NotNil(t, json.Unmarshal([]byte(`1e2000000000`), &huge))
+
+	d := NewDecimal(12345, 2)
+	assert.Equal(t, "123.45", d.String())
+	assert.Equal(t, 2, d.Scale())
+	assert.Equal(t, int64(12345), d.Unscaled().Int64())
+	assert.Equal(t, 123.45, d.Float64())
+	assert.Equal(t, 0, d.Cmp(NewDecimal(1234500, 4)))
+	assert.Equal(t, 1, d.Cmp(NewDecimal(-1, 0)))
+	assert.Equal(t, "0", Decimal{}.String())
+	assert.Equal(t, 0, Decimal{}.Sign())
+}
+
+func TestDecimalDecfloat(t *testing.T) {
+	fmt.Println("Decimal: DECF16 and DECF34 range")
+	for _, value := range []string{
+		testutils.RFC_MATH["DECF16"].(map[string]interface{})["POS"].(map[string]string)["MAX"],
+		testutils.RFC_MATH["DECF34"].(map[string]interface{})["NEG"].(map[string]string)["MAX"],
+		testutils.RFC_MATH["DECF34"].(map[string]interface{})["POS"].(map[string]string)["MIN"],
+	} {
+		d, err := ParseDecimal(value)
+		assert.Nil(t, err)
+		assert.Equal(t, value, d.decfloatString())
+	}
+	assert.Equal(t, "1.50", NewDecimal(150, 2).decfloatString())
+}
+
+func TestDecimalLimits(t *testing.T) {
+	fmt.Println("Decimal: Length and decimals")
+	// P(8) DECIMALS 2: 13 integer digits
+	assert.Nil(t, checkPacked(NewDecimal(999999999999999, 2), 8, 2))
+	assert.Nil(t, checkPacked(NewDecimal(-100, 2), 8, 2))
+	assert.Nil(t, checkPacked(NewDecimal(1230, 3), 8, 2))
+	assert.Nil(t, checkPacked(Decimal{}, 1, 0))
+	assert.Equal(t, "decimal 1.234 has 3 decimals, 2 allowed", checkPacked(NewDecimal(1234, 3), 8, 2).Error())
+	assert.Equal(t, "decimal 10000000000000.00 has 14 integer digits, 13 allowed",
+		checkPacked(NewDecimal(1000000000000000, 2), 8, 2).Error())
+
+	assert.Nil(t, checkDecfloat(NewDecimal(1234567890123456, 0), 16))
+	d, _ := ParseDecimal("1.2345678901234567")
+	assert.Equal(t, "decimal 1.2345678901234567 has 17 significant digits, 16 allowed", checkDecfloat(d, 16).Error())
+	assert.Nil(t, checkDecfloat(d, 34))
+}
+
+func TestDecimalOverflowRejected(t *testing.T) {
+	fmt.Println("Decimal: Overflow rejected when filling without strict validation")
+	assert.Equal(t, "decimal 10000000000000.00 has 14 integer digits, 13 allowed",
+		checkValue("RFCTYPE_BCD", 8, 2, NewDecimal(1000000000000000, 2), false).Error())
+	d, _ := ParseDecimal("1.2345678901234567")
+	assert.Equal(t, "decimal 1.2345678901234567 has 17 significant digits, 16 allowed",
+		checkValue("RFCTYPE_DECF16", 8, 0, d, false).Error())
+	assert.Nil(t, checkValue("RFCTYPE_DECF34", 16, 0, d, false))
+}
+
+func TestDecimalJSON(t *testing.T) {
+	fmt.Println("Decimal: JSON")
+	var amount struct {
+		Amount Decimal
+		Price  *Decimal
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"Amount": 12345678901234567.89, "Price": "0.10"}`), &amount))
+	assert.Equal(t, "12345678901234567.89", amount.Amount.String())
+	assert.Equal(t, "0.10", amount.Price.String())
+	data, err := json.Marshal(amount)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Amount":12345678901234567.89,"Price":0.10}`, string(data))
+}
+
+func TestDecodeDecimal(t *testing.T) {
+	fmt.Println("Decimal: Decode")
+	var item struct {
+		Amount  Decimal `rfc:"AMOUNT"`
+		Price   float64 `rfc:"PRICE"`
+		Display string  `rfc:"DISPLAY"`
+	}
+	err := Decode(map[string]interface{}{"AMOUNT": "1234.56", "PRICE": NewDecimal(10, 1), "DISPLAY": NewDecimal(-5, 0)}, &item)
+	assert.Nil(t, err)
+	assert.Equal(t, NewDecimal(123456, 2), item.Amount)
+	assert.Equal(t, 1.0, item.Price)
+	assert.Equal(t, "-5", item.Display)
+	assert.NotNil(t, Decode(map[string]interface{}{"AMOUNT": "12,5"}, &item))
+}
+
+func TestDecimalCall(t *testing.T) {
+	fmt.Println("Decimal: Call with Decimal input and output")
+	c, err := ConnectionFromDest("MME")
+	assert.Nil(t, err)
+	c.ReturnDecimal(true)
+	decf34, _ := ParseDecimal(testutils.RFC_MATH["DECF34"].(map[string]interface{})["POS"].(map[string]string)["MAX"])
+	r, err := c.Call("/COE/RBP_FE_DATATYPES", map[string]interface{}{
+		"IS_INPUT": map[string]interface{}{"ZDECF34_MAX": decf34},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, decf34.Cmp(r["ES_OUTPUT"].(map[string]interface{})["ZDECF34_MAX"].(Decimal)))
+
+	_, err = c.Call("STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCFLOAT": NewDecimal(15, 1), "RFCINT1": 1},
+	})
+	assert.Nil(t, err)
+
+	// overflowing amounts are rejected before the call, also without strict validation
+	_, err = c.Call("STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCDEC": NewDecimal(1, -13)},
+	})
+	assert.Equal(t, "Could not fill IMPORTSTRUCT.RFCDEC", err.(*GoRfcError).Description)
+	c.Close()
+}
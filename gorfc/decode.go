@@ -0,0 +1,207 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//################################################################################
+//# DECODE                                                                       #
+//################################################################################
+//# Decode functions take wrapped results and fill Go variables
+
+// Decode fills the Go structure pointed to by out with the RFC result.
+// Structures and tables are decoded into nested Go structures and slices.
+func Decode(result map[string]interface{}, out interface{}) (err error) {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return goRfcError(fmt.Sprintf("Decode requires a non-nil pointer to GO structure, got %v", reflect.TypeOf(out)), nil)
+	}
+	return decodeStructure(result, v.Elem(), "")
+}
+
+// CallInto calls the given function with the given parameters and decodes the results into out.
+func (conn *Connection) CallInto(goFuncName string, params interface{}, out interface{}) (err error) {
+	result, err := conn.Call(goFuncName, params)
+	if err != nil {
+		return
+	}
+	return Decode(result, out)
+}
+
+func decodeStructure(source map[string]interface{}, target reflect.Value, path string) (err error) {
+	for _, field := range structFields(target.Type()) {
+		value, ok := source[field.name]
+		if !ok {
+			continue
+		}
+		fieldValue, _ := fieldByIndex(target, field.index, true)
+		err = decodeValue(value, fieldValue, joinPath(path, field.name))
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func decodeError(value interface{}, target reflect.Value, path string, goerror error) error {
+	return goRfcError(fmt.Sprintf("Could not decode ABAP %T into GO %v field \"%v\"", value, target.Type(), path), goerror)
+}
+
+func decodeValue(value interface{}, target reflect.Value, path string) (err error) {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return
+	}
+
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(target.Type().Elem())
+		err = decodeValue(value, elem.Elem(), path)
+		if err == nil {
+			target.Set(elem)
+		}
+		return
+	case reflect.Interface:
+		if source.Type().Implements(target.Type()) {
+			target.Set(source)
+			return
+		}
+	case reflect.Struct:
+		if target.Type() == reflect.TypeOf(Decimal{}) && source.Kind() == reflect.String {
+			// BCD, DECF16, DECF34
+			var d Decimal
+			d, err = ParseDecimal(source.String())
+			if err != nil {
+				return decodeError(value, target, path, err)
+			}
+			target.Set(reflect.ValueOf(d))
+			return
+		}
+		if t, ok := value.(time.Time); ok && target.Type() == reflect.TypeOf(Date{}) {
+			target.Set(reflect.ValueOf(DateOf(t)))
+			return
+		}
+		if structure, ok := value.(map[string]interface{}); ok {
+			return decodeStructure(structure, target, path)
+		}
+	case reflect.Slice:
+		if lines, ok := value.([]interface{}); ok {
+			slice := reflect.MakeSlice(target.Type(), len(lines), len(lines))
+			for i, line := range lines {
+				err = decodeValue(line, slice.Index(i), fmt.Sprintf("%v[%v]", path, i))
+				if err != nil {
+					return
+				}
+			}
+			target.Set(slice)
+			return
+		}
+	case reflect.String:
+		if source.Kind() == reflect.String {
+			target.SetString(source.String())
+			return
+		}
+		if d, ok := value.(Decimal); ok {
+			target.SetString(d.String())
+			return
+		}
+	case reflect.Bool:
+		// ABAP flags: "X" is true, initial is false
+		if source.Kind() == reflect.String {
+			target.SetBool(strings.TrimSpace(source.String()) != "")
+			return
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = source.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			i = int64(source.Uint())
+		case reflect.String:
+			// NUMC
+			i, err = strconv.ParseInt(strings.TrimSpace(source.String()), 10, 64)
+			if err != nil {
+				return decodeError(value, target, path, err)
+			}
+		default:
+			return decodeError(value, target, path, nil)
+		}
+		if target.OverflowInt(i) {
+			return decodeError(value, target, path, fmt.Errorf("value %v overflows %v", i, target.Type()))
+		}
+		target.SetInt(i)
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch source.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if source.Int() < 0 {
+				return decodeError(value, target, path, fmt.Errorf("negative value %v", source.Int()))
+			}
+			u = uint64(source.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = source.Uint()
+		case reflect.String:
+			u, err = strconv.ParseUint(strings.TrimSpace(source.String()), 10, 64)
+			if err != nil {
+				return decodeError(value, target, path, err)
+			}
+		default:
+			return decodeError(value, target, path, nil)
+		}
+		if target.OverflowUint(u) {
+			return decodeError(value, target, path, fmt.Errorf("value %v overflows %v", u, target.Type()))
+		}
+		target.SetUint(u)
+		return
+	case reflect.Float32, reflect.Float64:
+		switch source.Kind() {
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(source.Float())
+			return
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			target.SetFloat(float64(source.Int()))
+			return
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			target.SetFloat(float64(source.Uint()))
+			return
+		case reflect.String:
+			// BCD, DECF16, DECF34
+			var f float64
+			f, err = strconv.ParseFloat(strings.TrimSpace(source.String()), 64)
+			if err != nil {
+				return decodeError(value, target, path, err)
+			}
+			target.SetFloat(f)
+			return
+		case reflect.Struct:
+			if d, ok := value.(Decimal); ok {
+				target.SetFloat(d.Float64())
+				return
+			}
+		}
+	}
+
+	return decodeError(value, target, path, nil)
+}
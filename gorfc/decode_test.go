@@ -0,0 +1,100 @@
+package gorfc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Decode Tests
+//
+
+type decodeLine struct {
+	Char    string `rfc:"RFCCHAR4"`
+	Int1    uint8  `rfc:"RFCINT1"`
+	Int4    int    `rfc:"RFCINT4"`
+	RFCDATE time.Time
+	Flag    bool   `rfc:"FLAG"`
+	Skipped string `rfc:"-"`
+}
+
+type decodeResult struct {
+	Echo    decodeLine    `rfc:"ECHOSTRUCT"`
+	Table   []decodeLine  `rfc:"RFCTABLE"`
+	Numc    int64         `rfc:"NUMC"`
+	Amount  float64       `rfc:"AMOUNT"`
+	Resptxt *string       `rfc:"RESPTEXT"`
+	Raw     []interface{} `rfc:"RAW"`
+}
+
+func TestDecode(t *testing.T) {
+	fmt.Println("Decode: structure, table and conversions")
+	date := time.Date(2020, 5, 4, 0, 0, 0, 0, time.UTC)
+	line := map[string]interface{}{
+		"RFCCHAR4": "ABCD",
+		"RFCINT1":  uint8(254),
+		"RFCINT4":  int32(-7),
+		"RFCDATE":  date,
+		"FLAG":     "X",
+	}
+	result := map[string]interface{}{
+		"ECHOSTRUCT": line,
+		"RFCTABLE":   []interface{}{line, map[string]interface{}{"RFCDATE": nil, "FLAG": ""}},
+		"NUMC":       "000123",
+		"AMOUNT":     "-12.50",
+		"RESPTEXT":   "SAP R/3",
+		"RAW":        []interface{}{"A", "B"},
+		"UNKNOWN":    "ignored",
+	}
+
+	var out decodeResult
+	out.Echo.Skipped = "keep"
+	err := Decode(result, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, "ABCD", out.Echo.Char)
+	assert.Equal(t, uint8(254), out.Echo.Int1)
+	assert.Equal(t, -7, out.Echo.Int4)
+	assert.Equal(t, date, out.Echo.RFCDATE)
+	assert.True(t, out.Echo.Flag)
+	assert.Equal(t, "keep", out.Echo.Skipped)
+	assert.Equal(t, 2, len(out.Table))
+	assert.Equal(t, "ABCD", out.Table[0].Char)
+	assert.True(t, out.Table[1].RFCDATE.IsZero())
+	assert.False(t, out.Table[1].Flag)
+	assert.Equal(t, int64(123), out.Numc)
+	assert.Equal(t, -12.5, out.Amount)
+	assert.Equal(t, "SAP R/3", *out.Resptxt)
+	assert.Equal(t, []interface{}{"A", "B"}, out.Raw)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	fmt.Println("Decode: type mismatch errors")
+	var out decodeResult
+	err := Decode(map[string]interface{}{"RFCTABLE": []interface{}{map[string]interface{}{"RFCINT1": int32(300)}}}, &out)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Could not decode ABAP int32 into GO uint8 field \"RFCTABLE[0].RFCINT1\"", err.(*GoRfcError).Description)
+
+	err = Decode(map[string]interface{}{"ECHOSTRUCT": "not a structure"}, &out)
+	assert.Equal(t, "Could not decode ABAP string into GO gorfc.decodeLine field \"ECHOSTRUCT\"", err.(*GoRfcError).Description)
+
+	err = Decode(map[string]interface{}{}, out)
+	assert.Equal(t, "Decode requires a non-nil pointer to GO structure, got gorfc.decodeResult", err.(*GoRfcError).Description)
+}
+
+func TestCallInto(t *testing.T) {
+	fmt.Println("Decode: CallInto")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	var out struct {
+		Echo     string `rfc:"ECHOTEXT"`
+		Response string `rfc:"RESPTEXT"`
+	}
+	err = c.CallInto("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"}, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hällö", out.Echo)
+	assert.NotEmpty(t, out.Response)
+	c.Close()
+}
@@ -0,0 +1,134 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//################################################################################
+//# DESTINATIONS                                                                 #
+//################################################################################
+//# sapnwrfc.ini destinations read in Go, independent of the SAP NW RFC library
+
+// IniFileName is the name of the SAP NW RFC library destinations file
+const IniFileName = "sapnwrfc.ini"
+
+// directory set by SetIniPath
+var iniPath = struct {
+	sync.Mutex
+	dir string
+}{}
+
+// Destinations holds the connection parameters of sapnwrfc.ini destinations, by destination name.
+// Parameter names are lower case, like ConnectionParameters passed to ConnectionFromParams.
+type Destinations map[string]ConnectionParameters
+
+// ReadDestinations reads sapnwrfc.ini from the directory set by SetIniPath, or from the current directory
+func ReadDestinations() (destinations Destinations, err error) {
+	iniPath.Lock()
+	dir := iniPath.dir
+	iniPath.Unlock()
+	return ReadIniFile(filepath.Join(dir, IniFileName))
+}
+
+// ReadIniFile reads the destinations from the sapnwrfc.ini file at path
+func ReadIniFile(path string) (destinations Destinations, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, goRfcError(fmt.Sprintf("Could not open %v", path), err)
+	}
+	defer file.Close()
+	return ParseIniFile(file)
+}
+
+// ParseIniFile parses the destinations in sapnwrfc.ini format. Each destination starts with
+// the DEST=name line, followed by name=value parameter lines. Lines starting with # are comments.
+// Parameters after the DEFAULT line, like RFC_TRACE, are returned as "DEFAULT" destination.
+func ParseIniFile(r io.Reader) (destinations Destinations, err error) {
+	destinations = make(Destinations)
+	var params ConnectionParameters
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		name := line
+		value := ""
+		if i >= 0 {
+			name = strings.ToLower(strings.TrimSpace(line[:i]))
+			value = strings.TrimSpace(line[i+1:])
+		}
+
+		switch {
+		case strings.EqualFold(name, "DEFAULT") && i < 0:
+			name, value = "dest", "DEFAULT"
+		case i < 1:
+			return nil, goRfcError(fmt.Sprintf("%v line %v: \"%v\" not in name=value form", IniFileName, lineNumber, line), nil)
+		}
+
+		if name == "dest" {
+			if value == "" {
+				return nil, goRfcError(fmt.Sprintf("%v line %v: empty destination name", IniFileName, lineNumber), nil)
+			}
+			if _, ok := destinations[value]; ok {
+				return nil, goRfcError(fmt.Sprintf("%v line %v: duplicate destination %v", IniFileName, lineNumber, value), nil)
+			}
+			params = make(ConnectionParameters)
+			destinations[value] = params
+			continue
+		}
+
+		if params == nil {
+			return nil, goRfcError(fmt.Sprintf("%v line %v: parameter %v before first DEST", IniFileName, lineNumber, name), nil)
+		}
+		if _, ok := params[name]; ok {
+			return nil, goRfcError(fmt.Sprintf("%v line %v: duplicate parameter %v", IniFileName, lineNumber, name), nil)
+		}
+		params[name] = value
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, goRfcError(fmt.Sprintf("Could not read %v", IniFileName), err)
+	}
+	return
+}
+
+// Names returns the sorted destination names
+func (destinations Destinations) Names() (names []string) {
+	for name := range destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Get returns a copy of the destination connection parameters, merged with the overrides
+func (destinations Destinations) Get(dest string, overrides ...ConnectionParameters) (params ConnectionParameters, err error) {
+	destParams, ok := destinations[dest]
+	if !ok {
+		return nil, goRfcError(fmt.Sprintf("Destination %v not found", dest), nil)
+	}
+	return MergeParams(append([]ConnectionParameters{destParams}, overrides...)...), nil
+}
+
+// MergeParams returns the connection parameters merged, the latter overriding the former
+func MergeParams(params ...ConnectionParameters) (merged ConnectionParameters) {
+	merged = make(ConnectionParameters)
+	for _, p := range params {
+		for name, value := range p {
+			merged[name] = value
+		}
+	}
+	return
+}
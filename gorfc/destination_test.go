@@ -0,0 +1,89 @@
+package gorfc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Destinations Tests
+//
+
+func TestParseIniFile(t *testing.T) {
+	fmt.Println("Destinations: Parse sapnwrfc.ini")
+	destinations, err := ParseIniFile(strings.NewReader(`
+# trace settings
+DEFAULT
+RFC_TRACE=0
+
+DEST=MME
+USER=demo
+PASSWD=welcome=1
+ASHOST=10.68.110.51
+SYSNR=00
+
+dest = QM7
+user = NWRFCTEST
+mshost = ldciqm7
+sysid = QM7
+group = PUBLIC
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DEFAULT", "MME", "QM7"}, destinations.Names())
+	assert.Equal(t, ConnectionParameters{"rfc_trace": "0"}, destinations["DEFAULT"])
+	assert.Equal(t, ConnectionParameters{"user": "demo", "passwd": "welcome=1", "ashost": "10.68.110.51", "sysnr": "00"}, destinations["MME"])
+	assert.Equal(t, "PUBLIC", destinations["QM7"]["group"])
+
+	params, err := destinations.Get("MME", ConnectionParameters{"user": "other", "client": "620"})
+	assert.Nil(t, err)
+	assert.Equal(t, "other", params["user"])
+	assert.Equal(t, "620", params["client"])
+	assert.Equal(t, "demo", destinations["MME"]["user"])
+	_, err = destinations.Get("XXX")
+	assert.NotNil(t, err)
+}
+
+func TestParseIniFileErrors(t *testing.T) {
+	fmt.Println("Destinations: Invalid sapnwrfc.ini")
+	for ini, message := range map[string]string{
+		"USER=demo\nDEST=MME":      "sapnwrfc.ini line 1: parameter user before first DEST",
+		"DEST=MME\nUSER":           "sapnwrfc.ini line 2: \"USER\" not in name=value form",
+		"DEST=MME\nDEST=MME":       "sapnwrfc.ini line 2: duplicate destination MME",
+		"DEST=MME\nUSER=a\nUSER=b": "sapnwrfc.ini line 3: duplicate parameter user",
+		"DEST=\nUSER=a":            "sapnwrfc.ini line 1: empty destination name",
+	} {
+		_, err := ParseIniFile(strings.NewReader(ini))
+		assert.Equal(t, message, err.(*GoRfcError).Description)
+	}
+}
+
+func TestReadIniFile(t *testing.T) {
+	fmt.Println("Destinations: Read sapnwrfc.ini")
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, IniFileName), []byte("DEST=MME\nASHOST=10.68.110.51\n"), 0600))
+	destinations, err := ReadIniFile(filepath.Join(dir, IniFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, "10.68.110.51", destinations["MME"]["ashost"])
+	_, err = ReadIniFile(filepath.Join(dir, "missing.ini"))
+	assert.NotNil(t, err)
+}
+
+func TestSetIniPath(t *testing.T) {
+	fmt.Println("Destinations: Set ini path")
+	dir, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, SetIniPath(dir))
+	assert.Nil(t, ReloadIniFile())
+	destinations, err := ReadDestinations()
+	assert.Nil(t, err)
+	assert.Contains(t, destinations.Names(), "MME")
+	c, err := ConnectionFromDest("MME")
+	assert.Nil(t, err)
+	assert.True(t, c.Alive())
+	c.Close()
+}
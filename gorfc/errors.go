@@ -0,0 +1,210 @@
+package gorfc
+
+import (
+	"fmt"
+	"strings"
+)
+
+//################################################################################
+//# ERRORS                                                             	 	     #
+//################################################################################
+
+// RfcErrorInfo holds the RFC_ERROR_INFO returned by SAP NWRFC SDK
+type RfcErrorInfo struct {
+	Message       string
+	Code          string
+	Group         ErrorGroup
+	Key           string
+	AbapMsgClass  string
+	AbapMsgType   string
+	AbapMsgNumber string
+	AbapMsgV1     string
+	AbapMsgV2     string
+	AbapMsgV3     string
+	AbapMsgV4     string
+}
+
+func (errorInfo RfcErrorInfo) String() string {
+	return fmt.Sprintf("RfcErrorInfo[%v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v]", errorInfo.Message, errorInfo.Code, errorInfo.Group, errorInfo.Key, errorInfo.AbapMsgClass, errorInfo.AbapMsgType, errorInfo.AbapMsgNumber, errorInfo.AbapMsgV1, errorInfo.AbapMsgV2, errorInfo.AbapMsgV3, errorInfo.AbapMsgV4)
+}
+
+// ErrorGroup classifies the RFC error, as RFC_ERROR_GROUP.
+// ErrorGroup can be used with errors.Is, like errors.Is(err, CommunicationFailure).
+type ErrorGroup int
+
+// RFC_ERROR_GROUP values
+const (
+	ErrorGroupOK ErrorGroup = iota
+	AbapApplicationFailure
+	AbapRuntimeFailure
+	LogonFailure
+	CommunicationFailure
+	ExternalRuntimeFailure
+	ExternalApplicationFailure
+	ExternalAuthorizationFailure
+	ExternalAuthenticationFailure
+	CryptolibFailure
+	LockingFailure
+)
+
+var errorGroupNames = []string{
+	"OK",
+	"ABAP_APPLICATION_FAILURE",
+	"ABAP_RUNTIME_FAILURE",
+	"LOGON_FAILURE",
+	"COMMUNICATION_FAILURE",
+	"EXTERNAL_RUNTIME_FAILURE",
+	"EXTERNAL_APPLICATION_FAILURE",
+	"EXTERNAL_AUTHORIZATION_FAILURE",
+	"EXTERNAL_AUTHENTICATION_FAILURE",
+	"CRYPTOLIB_FAILURE",
+	"LOCKING_FAILURE",
+}
+
+func (group ErrorGroup) String() string {
+	if group >= 0 && int(group) < len(errorGroupNames) {
+		return errorGroupNames[group]
+	}
+	return fmt.Sprintf("ErrorGroup(%d)", int(group))
+}
+
+func (group ErrorGroup) Error() string {
+	return group.String()
+}
+
+// RfcError is returned by SAP NWRFC SDK
+type RfcError struct {
+	Description string
+	ErrorInfo   RfcErrorInfo
+}
+
+func (err RfcError) Error() string {
+	return fmt.Sprintf("NWRFC SDK error: %s | %s", err.Description, err.ErrorInfo)
+}
+
+// Is reports if the error belongs to the target ErrorGroup
+func (err *RfcError) Is(target error) bool {
+	group, ok := target.(ErrorGroup)
+	return ok && group == err.ErrorInfo.Group
+}
+
+// As sets the target to the error type of the RfcError group
+func (err *RfcError) As(target interface{}) bool {
+	group := err.ErrorInfo.Group
+	switch t := target.(type) {
+	case *AbapApplicationError:
+		if group == AbapApplicationFailure {
+			*t = AbapApplicationError{err}
+			return true
+		}
+	case *AbapRuntimeError:
+		if group == AbapRuntimeFailure {
+			*t = AbapRuntimeError{err}
+			return true
+		}
+	case *LogonError:
+		if group == LogonFailure {
+			*t = LogonError{err}
+			return true
+		}
+	case *CommunicationError:
+		if group == CommunicationFailure {
+			*t = CommunicationError{err}
+			return true
+		}
+	case *ExternalRuntimeError:
+		if group == ExternalRuntimeFailure {
+			*t = ExternalRuntimeError{err}
+			return true
+		}
+	case *ExternalApplicationError:
+		if group == ExternalApplicationFailure {
+			*t = ExternalApplicationError{err}
+			return true
+		}
+	case *ExternalAuthorizationError:
+		if group == ExternalAuthorizationFailure {
+			*t = ExternalAuthorizationError{err}
+			return true
+		}
+	}
+	return false
+}
+
+// AbapApplicationError is the RfcError of ABAP exception or ABAP message raised by the function module
+type AbapApplicationError struct{ *RfcError }
+
+// AbapRuntimeError is the RfcError of ABAP runtime error or ABAP message of type A or X
+type AbapRuntimeError struct{ *RfcError }
+
+// LogonError is the RfcError of failed logon
+type LogonError struct{ *RfcError }
+
+// CommunicationError is the RfcError of broken or not established network connection
+type CommunicationError struct{ *RfcError }
+
+// ExternalRuntimeError is the RfcError of SAP NWRFC SDK runtime failure
+type ExternalRuntimeError struct{ *RfcError }
+
+// ExternalApplicationError is the RfcError returned by the server function
+type ExternalApplicationError struct{ *RfcError }
+
+// ExternalAuthorizationError is the RfcError of missing authorization
+type ExternalAuthorizationError struct{ *RfcError }
+
+// GoRfcError is returned by gorfc
+type GoRfcError struct {
+	Description string
+	GoError     error
+}
+
+func (err GoRfcError) Error() string {
+	if err.GoError != nil {
+		return fmt.Sprintf("GORFC error: %s | %s", err.Description, err.GoError.Error())
+	}
+	return fmt.Sprintf("GORFC error: %s", err.Description)
+}
+
+// Unwrap returns the underlying Go error, if any
+func (err GoRfcError) Unwrap() error {
+	return err.GoError
+}
+
+func goRfcError(description string, goerror error) *GoRfcError {
+	return &GoRfcError{description, goerror}
+}
+
+// FillError is the error of the parameter or field value at Path, like "IT_ITEMS[3].MATNR", that could not be filled
+type FillError struct {
+	Path string
+	Err  error
+}
+
+func (err FillError) Error() string {
+	return err.Path + ": " + err.Err.Error()
+}
+
+// Unwrap returns the RfcError or GoRfcError of the value
+func (err FillError) Unwrap() error {
+	return err.Err
+}
+
+// FillErrors lists the errors of all values that could not be filled, collected by connections set by CollectFillErrors(true)
+type FillErrors []FillError
+
+func (errs FillErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add returns err if errs is nil, to stop filling. Otherwise err is collected and nil returned, to fill the remaining values.
+func (errs *FillErrors) add(path string, err error) error {
+	if errs == nil || err == nil {
+		return err
+	}
+	*errs = append(*errs, FillError{path, err})
+	return nil
+}
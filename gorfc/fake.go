@@ -0,0 +1,280 @@
+package gorfc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//################################################################################
+//# FAKE CONNECTION                                                              #
+//################################################################################
+//# In-memory ABAP system for tests, with function modules implemented in Go
+
+// FakeFunction implements the function module of the FakeConnection.
+// The params hold the parameters passed to Call, the result is returned by Call.
+type FakeFunction func(params map[string]interface{}) (result map[string]interface{}, err error)
+
+// FakeCall records the function call handled by the FakeConnection
+type FakeCall struct {
+	Name   string
+	Params map[string]interface{}
+	Result map[string]interface{}
+	Err    error
+}
+
+// FakeConnection is the Client handling calls with Go functions, without ABAP system
+type FakeConnection struct {
+	mu        sync.Mutex
+	alive     bool
+	connAttr  ConnectionAttributes
+	handlers  map[string]FakeFunction
+	funcDescs map[string]FunctionDescription
+	failures  []error
+	calls     []FakeCall
+}
+
+var _ Client = (*FakeConnection)(nil)
+
+// NewFakeConnection returns the open fake connection with given connection attributes
+func NewFakeConnection(connAttr ConnectionAttributes) *FakeConnection {
+	if connAttr == nil {
+		connAttr = ConnectionAttributes{"sysId": "FAK", "client": "000", "user": "FAKE", "rfcRole": "C"}
+	}
+	return &FakeConnection{
+		alive:     true,
+		connAttr:  connAttr,
+		handlers:  make(map[string]FakeFunction),
+		funcDescs: make(map[string]FunctionDescription),
+	}
+}
+
+// AddFunction registers the Go function handling the calls of goFuncName and returns the fake connection
+func (fake *FakeConnection) AddFunction(goFuncName string, handler FakeFunction) *FakeConnection {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.handlers[goFuncName] = handler
+	return fake
+}
+
+// AddFunctionDescription registers the function description and returns the fake connection.
+// Calls of described functions are rejected for parameter names not in the description.
+func (fake *FakeConnection) AddFunctionDescription(funcDesc FunctionDescription) *FakeConnection {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.funcDescs[funcDesc.Name] = funcDesc
+	return fake
+}
+
+// FailNext makes the next call or ping return err, instead of calling the Go function.
+// The connection is closed if err is a CommunicationFailure.
+func (fake *FakeConnection) FailNext(err error) *FakeConnection {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.failures = append(fake.failures, err)
+	return fake
+}
+
+// Calls returns the function calls handled so far
+func (fake *FakeConnection) Calls() []FakeCall {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]FakeCall(nil), fake.calls...)
+}
+
+// Alive returns true if the fake connection is open
+func (fake *FakeConnection) Alive() bool {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.alive
+}
+
+// Open opens the fake connection
+func (fake *FakeConnection) Open() (err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.alive = true
+	return
+}
+
+// Reopen closes and opens the fake connection
+func (fake *FakeConnection) Reopen() (err error) {
+	return fake.Open()
+}
+
+// Close closes the fake connection
+func (fake *FakeConnection) Close() (err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.alive = false
+	return
+}
+
+// Ping returns the error set by FailNext, if any
+func (fake *FakeConnection) Ping() (err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.alive = true
+	return fake.nextFailure()
+}
+
+// GetConnectionAttributes returns the connection attributes of the fake connection
+func (fake *FakeConnection) GetConnectionAttributes() (connAttr ConnectionAttributes, err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if !fake.alive {
+		return nil, goRfcError("GetConnectionAttributes() method requires an open connection", nil)
+	}
+	connAttr = make(ConnectionAttributes, len(fake.connAttr))
+	for name, value := range fake.connAttr {
+		connAttr[name] = value
+	}
+	return
+}
+
+// GetFunctionDescription returns the registered function description
+func (fake *FakeConnection) GetFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.alive = true
+	goFuncDesc, ok := fake.funcDescs[goFuncName]
+	if !ok {
+		return goFuncDesc, functionNotFound(goFuncName)
+	}
+	return
+}
+
+// Call calls the Go function registered for goFuncName
+func (fake *FakeConnection) Call(goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	return fake.CallContext(context.Background(), goFuncName, params)
+}
+
+// CallContext calls the Go function registered for goFuncName, unless ctx is done
+func (fake *FakeConnection) CallContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	fake.mu.Lock()
+	if !fake.alive {
+		fake.mu.Unlock()
+		return nil, goRfcError("Call() method requires an open connection", nil)
+	}
+	if ctx.Err() != nil {
+		fake.mu.Unlock()
+		return nil, goRfcError(fmt.Sprintf("Call of \"%v\" not started", goFuncName), ctx.Err())
+	}
+	failure := fake.nextFailure()
+	handler, ok := fake.handlers[goFuncName]
+	funcDesc, described := fake.funcDescs[goFuncName]
+	fake.mu.Unlock()
+
+	goParams := make(map[string]interface{})
+	err = forEachParameter(params, func(goName string, value interface{}) error {
+		if described && !hasParameter(funcDesc, goName) {
+			return &RfcError{
+				fmt.Sprintf("Could not get the parameter description for \"%v\"", goName),
+				RfcErrorInfo{Message: fmt.Sprintf("field '%v' not found", goName), Code: "RFC_INVALID_PARAMETER", Group: ExternalRuntimeFailure},
+			}
+		}
+		goParams[goName] = value
+		return nil
+	})
+
+	switch {
+	case err != nil:
+	case failure != nil:
+		err = failure
+	case !ok && !described:
+		err = functionNotFound(goFuncName)
+	case !ok:
+		// described only, no results
+	default:
+		result, err = handler(goParams)
+		var rfcErr *RfcError
+		if errors.As(err, &rfcErr) {
+			err = &RfcError{fmt.Sprintf("Could not invoke function \"%v\"", goFuncName), rfcErr.ErrorInfo}
+		}
+	}
+	if err != nil {
+		result = nil
+	}
+
+	fake.mu.Lock()
+	fake.calls = append(fake.calls, FakeCall{goFuncName, goParams, result, err})
+	if errors.Is(err, CommunicationFailure) {
+		fake.alive = false
+	}
+	fake.mu.Unlock()
+	return
+}
+
+func (fake *FakeConnection) nextFailure() (err error) {
+	if len(fake.failures) == 0 {
+		return
+	}
+	err = fake.failures[0]
+	fake.failures = fake.failures[1:]
+	if errors.Is(err, CommunicationFailure) {
+		fake.alive = false
+	}
+	return
+}
+
+func hasParameter(funcDesc FunctionDescription, goName string) bool {
+	for _, paramDesc := range funcDesc.Parameters {
+		if paramDesc.Name == goName {
+			return true
+		}
+	}
+	return false
+}
+
+func functionNotFound(goFuncName string) *RfcError {
+	return &RfcError{
+		fmt.Sprintf("Could not get function description for \"%v\"", goFuncName),
+		RfcErrorInfo{
+			Message:       fmt.Sprintf("ID:FL Type:E Number:046 %v", goFuncName),
+			Code:          "RFC_ABAP_EXCEPTION",
+			Group:         AbapApplicationFailure,
+			Key:           "FU_NOT_FOUND",
+			AbapMsgClass:  "FL",
+			AbapMsgType:   "E",
+			AbapMsgNumber: "046",
+			AbapMsgV1:     goFuncName,
+		},
+	}
+}
+
+// NewAbapException returns the error of the ABAP exception key, raised by the FakeFunction or ServerFunction
+func NewAbapException(key string, message string) *RfcError {
+	return &RfcError{
+		fmt.Sprintf("ABAP exception %v", key),
+		RfcErrorInfo{Message: message, Code: "RFC_ABAP_EXCEPTION", Group: AbapApplicationFailure, Key: key},
+	}
+}
+
+// NewAbapMessage returns the error of the ABAP message, raised by the FakeFunction or ServerFunction
+func NewAbapMessage(msgClass string, msgType string, msgNumber string, msgV ...string) *RfcError {
+	msgV = append(msgV, "", "", "", "")
+	return &RfcError{
+		fmt.Sprintf("ABAP message %v %v %v", msgClass, msgType, msgNumber),
+		RfcErrorInfo{
+			Message:       fmt.Sprintf("ID:%v Type:%v Number:%v %v %v %v %v", msgClass, msgType, msgNumber, msgV[0], msgV[1], msgV[2], msgV[3]),
+			Code:          "RFC_ABAP_MESSAGE",
+			Group:         AbapApplicationFailure,
+			AbapMsgClass:  msgClass,
+			AbapMsgType:   msgType,
+			AbapMsgNumber: msgNumber,
+			AbapMsgV1:     msgV[0],
+			AbapMsgV2:     msgV[1],
+			AbapMsgV3:     msgV[2],
+			AbapMsgV4:     msgV[3],
+		},
+	}
+}
+
+// NewCommunicationFailure returns the error of broken network connection, for FailNext or the FakeFunction
+func NewCommunicationFailure(message string) *RfcError {
+	return &RfcError{
+		"Communication failure",
+		RfcErrorInfo{Message: message, Code: "RFC_COMMUNICATION_FAILURE", Group: CommunicationFailure},
+	}
+}
@@ -0,0 +1,108 @@
+package gorfc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Fake Connection Tests
+//
+
+func fakeSystem() *FakeConnection {
+	return NewFakeConnection(nil).
+		AddFunctionDescription(FunctionDescription{
+			Name: "STFC_CONNECTION",
+			Parameters: []ParameterDescription{
+				{Name: "ECHOTEXT", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_EXPORT", NucLength: 255, UcLength: 510},
+				{Name: "RESPTEXT", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_EXPORT", NucLength: 255, UcLength: 510},
+				{Name: "REQUTEXT", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_IMPORT", NucLength: 255, UcLength: 510},
+			},
+		}).
+		AddFunction("STFC_CONNECTION", func(params map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"ECHOTEXT": params["REQUTEXT"], "RESPTEXT": "SAP R/3 Rel. 750"}, nil
+		}).
+		AddFunction("BAPI_USER_GET_DETAIL", func(params map[string]interface{}) (map[string]interface{}, error) {
+			return nil, NewAbapMessage("01", "E", "124", fmt.Sprint(params["USERNAME"]))
+		}).
+		AddFunction("RFC_RAISE_ERROR", func(params map[string]interface{}) (map[string]interface{}, error) {
+			return nil, NewAbapException("RAISE_EXCEPTION", "RAISE_EXCEPTION")
+		})
+}
+
+func TestFakeCall(t *testing.T) {
+	fmt.Println("Fake: Call Go function")
+	var c Client = fakeSystem()
+	assert.Nil(t, c.Ping())
+
+	r, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Hällö", r["ECHOTEXT"])
+
+	type stfcConnection struct {
+		REQUTEXT string
+	}
+	r, err = c.Call("STFC_CONNECTION", stfcConnection{"Hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello", r["ECHOTEXT"])
+
+	attributes, err := c.GetConnectionAttributes()
+	assert.Nil(t, err)
+	assert.Equal(t, "FAK", attributes["sysId"])
+
+	d, err := c.GetFunctionDescription("STFC_CONNECTION")
+	assert.Nil(t, err)
+	assert.Equal(t, "REQUTEXT", d.Parameters[2].Name)
+
+	calls := c.(*FakeConnection).Calls()
+	assert.Equal(t, 2, len(calls))
+	assert.Equal(t, "STFC_CONNECTION", calls[1].Name)
+	assert.Equal(t, map[string]interface{}{"REQUTEXT": "Hello"}, calls[1].Params)
+	assert.Nil(t, c.Close())
+}
+
+func TestFakeErrors(t *testing.T) {
+	fmt.Println("Fake: ABAP errors")
+	c := fakeSystem()
+
+	_, err := c.Call("STFC_CONNECTION", map[string]interface{}{"XXX": "Hello"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "RFC_INVALID_PARAMETER", err.(*RfcError).ErrorInfo.Code)
+
+	_, err = c.Call("RFC_RAISE_ERROR", nil)
+	var abapError AbapApplicationError
+	assert.True(t, errors.As(err, &abapError))
+	assert.Equal(t, "RAISE_EXCEPTION", abapError.ErrorInfo.Key)
+	assert.Equal(t, "Could not invoke function \"RFC_RAISE_ERROR\"", abapError.Description)
+
+	_, err = c.Call("BAPI_USER_GET_DETAIL", map[string]interface{}{"USERNAME": "NOBODY"})
+	assert.True(t, errors.Is(err, AbapApplicationFailure))
+	assert.Equal(t, "01", err.(*RfcError).ErrorInfo.AbapMsgClass)
+	assert.Equal(t, "NOBODY", err.(*RfcError).ErrorInfo.AbapMsgV1)
+
+	_, err = c.Call("Z_NOT_FOUND", nil)
+	assert.Equal(t, "FU_NOT_FOUND", err.(*RfcError).ErrorInfo.Key)
+	_, err = c.GetFunctionDescription("Z_NOT_FOUND")
+	assert.Equal(t, "FU_NOT_FOUND", err.(*RfcError).ErrorInfo.Key)
+}
+
+func TestFakeCommunicationFailure(t *testing.T) {
+	fmt.Println("Fake: Communication failure")
+	c := fakeSystem()
+	c.FailNext(NewCommunicationFailure("connection reset by peer"))
+
+	_, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hello"})
+	var commError CommunicationError
+	assert.True(t, errors.As(err, &commError))
+	assert.False(t, c.Alive())
+
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hello"})
+	assert.Equal(t, "Call() method requires an open connection", err.(*GoRfcError).Description)
+
+	assert.Nil(t, c.Reopen())
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hello"})
+	assert.Nil(t, err)
+}
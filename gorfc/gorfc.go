@@ -74,10 +74,13 @@ static unsigned GoStrlenU(SAP_UTF16 *str) {
 import "C"
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 	"unsafe"
 )
@@ -100,41 +103,6 @@ static uint GoStrlenU(SAP_UTF16 *str) {
 }
 */
 
-//################################################################################
-//# ERRORS                                                             	 	     #
-//################################################################################
-
-// RfcError is returned by SAP NWRFC SDK
-type RfcError struct {
-	Description string
-	ErrorInfo   rfcSDKError
-}
-
-func (err RfcError) Error() string {
-	return fmt.Sprintf("NWRFC SDK error: %s | %s", err.Description, err.ErrorInfo)
-}
-
-func rfcError(errorInfo C.RFC_ERROR_INFO, format string, a ...interface{}) *RfcError {
-	return &RfcError{fmt.Sprintf(format, a...), wrapError(&errorInfo)}
-}
-
-// GoRfcError is returned by gorfc
-type GoRfcError struct {
-	Description string
-	GoError     error
-}
-
-func (err GoRfcError) Error() string {
-	if err.GoError != nil {
-		return fmt.Sprintf("GORFC error: %s | %s", err.Description, err.GoError.Error())
-	}
-	return fmt.Sprintf("GORFC error: %s", err.Description)
-}
-
-func goRfcError(description string, goerror error) *GoRfcError {
-	return &GoRfcError{description, goerror}
-}
-
 //################################################################################
 //# FILL FUNCTIONS                                                            	 #
 //################################################################################
@@ -157,7 +125,23 @@ func fillString(gostr string) (sapuc *C.SAP_UC, err error) {
 	return
 }
 
-func fillFunctionParameter(funcDesc C.RFC_FUNCTION_DESC_HANDLE, container C.RFC_FUNCTION_HANDLE, goName string, value interface{}) (err error) {
+// fillBuffer copies the Go string into the zero terminated SAP_UC buffer, truncated to the buffer size
+func fillBuffer(buffer []C.SAP_UC, gostr string) (err error) {
+	sapuc, err := fillString(gostr)
+	defer C.free(unsafe.Pointer(sapuc))
+	if err != nil {
+		return
+	}
+	n := int(C.GoStrlenU((*C.SAP_UTF16)(sapuc)))
+	if n > len(buffer)-1 {
+		n = len(buffer) - 1
+	}
+	copy(buffer, unsafe.Slice(sapuc, n))
+	buffer[n] = 0
+	return
+}
+
+func fillFunctionParameter(funcDesc C.RFC_FUNCTION_DESC_HANDLE, container C.RFC_FUNCTION_HANDLE, goName string, value interface{}, errs *FillErrors) (err error) {
 	var rc C.RFC_RC
 	var errorInfo C.RFC_ERROR_INFO
 	var paramDesc C.RFC_PARAMETER_DESC
@@ -170,13 +154,22 @@ func fillFunctionParameter(funcDesc C.RFC_FUNCTION_DESC_HANDLE, container C.RFC_
 
 	rc = C.RfcGetParameterDescByName(funcDesc, name, &paramDesc, &errorInfo)
 	if rc != C.RFC_OK {
-		return rfcError(errorInfo, "Could not get the parameter description for \"%v\"", goName)
+		return errs.add(goName, rfcError(errorInfo, "Could not get the parameter description for \"%v\"", goName))
 	}
 
-	return fillVariable(paramDesc._type, container, (*C.SAP_UC)(&paramDesc.name[0]), value, paramDesc.typeDescHandle)
+	return fillVariable(paramDesc._type, container, (*C.SAP_UC)(&paramDesc.name[0]), value, paramDesc.typeDescHandle, paramDesc.nucLength, paramDesc.decimals, goName, errs)
 }
 
-func fillVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP_UC, value interface{}, typeDesc C.RFC_TYPE_DESC_HANDLE) (err error) {
+// fillFunctionParameters fills the parameters passed as map or Go structure, stopping at the first value
+// that can't be filled. With errs set, such values are collected in errs and the remaining values filled.
+func fillFunctionParameters(funcDesc C.RFC_FUNCTION_DESC_HANDLE, funcCont C.RFC_FUNCTION_HANDLE, params interface{}, errs *FillErrors) (err error) {
+	return forEachParameter(params, func(goName string, value interface{}) error {
+		return fillFunctionParameter(funcDesc, funcCont, goName, value, errs)
+	})
+}
+
+// fillVariable fills the value at path, like "IT_ITEMS[3].MATNR", the errors of values are added to errs
+func fillVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP_UC, value interface{}, typeDesc C.RFC_TYPE_DESC_HANDLE, nucLength C.uint, decimals C.uint, path string, errs *FillErrors) (err error) {
 	var rc C.RFC_RC
 	var errorInfo C.RFC_ERROR_INFO
 	var structure C.RFC_STRUCTURE_HANDLE
@@ -187,22 +180,44 @@ func fillVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 	defer C.free(unsafe.Pointer(cValue))
 	defer C.free(unsafe.Pointer(bValue))
 
+	value, ok := indirect(value)
+	if !ok {
+		// nil leaves the ABAP initial value
+		return
+	}
+
+	// Go values which can't be converted are rejected here, lengths and value ranges are checked by strict validation
+	valueError := func(err error) error {
+		return errs.add(path, goRfcError(fmt.Sprintf("Could not fill %v", path), err))
+	}
+	var rfcType string
+	if cType != C.RFCTYPE_STRUCTURE && cType != C.RFCTYPE_TABLE {
+		rfcType, err = wrapString((*C.SAP_UC)(C.RfcGetTypeAsString(cType)), false)
+		if err != nil {
+			return
+		}
+		err = checkValue(rfcType, uint(nucLength), uint(decimals), value, false)
+		if err != nil {
+			return valueError(err)
+		}
+	}
+
 	switch cType {
 	case C.RFCTYPE_STRUCTURE:
 		rc = C.RfcGetStructure(container, cName, &structure, &errorInfo)
 		if rc != C.RFC_OK {
-			return rfcError(errorInfo, "Could not get structure")
+			return errs.add(path, rfcError(errorInfo, "Could not get structure %v", path))
 		}
-		err = fillStructure(typeDesc, structure, value)
+		err = fillStructure(typeDesc, structure, value, path, errs)
 	case C.RFCTYPE_TABLE:
 		if reflect.TypeOf(value).String()[:1] != "[" {
-			return goRfcError(fmt.Sprintf("GO %s passed to ABAP TABLE parameter, expected GO array", reflect.TypeOf(value).String()), nil)
+			return errs.add(path, goRfcError(fmt.Sprintf("GO %s passed to ABAP TABLE parameter %v, expected GO array", reflect.TypeOf(value).String(), path), nil))
 		}
 		rc = C.RfcGetTable(container, cName, &table, &errorInfo)
 		if rc != C.RFC_OK {
-			return rfcError(errorInfo, "Could not get table")
+			return errs.add(path, rfcError(errorInfo, "Could not get table %v", path))
 		}
-		err = fillTable(typeDesc, table, value)
+		err = fillTable(typeDesc, table, value, path, errs)
 	case C.RFCTYPE_BYTE:
 		bValue = (*C.SAP_RAW)(C.CBytes(reflect.ValueOf(value).Bytes()))
 		cLen := C.uint(len(reflect.ValueOf(value).Bytes()))
@@ -212,6 +227,12 @@ func fillVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		cLen := C.uint(len(reflect.ValueOf(value).Bytes()))
 		rc = C.RfcSetXString(container, cName, bValue, cLen, &errorInfo)
 	case C.RFCTYPE_CHAR:
+		if flag, ok := value.(bool); ok {
+			value = ""
+			if flag {
+				value = "X"
+			}
+		}
 		cValue, err = fillString(reflect.ValueOf(value).String())
 		//cLen := C.uint(len(reflect.ValueOf(value).String()))
 		cLen := C.uint(C.GoStrlenU((*C.SAP_UTF16)(cValue)))
@@ -222,7 +243,12 @@ func fillVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		cLen := C.uint(C.GoStrlenU((*C.SAP_UTF16)(cValue)))
 		rc = C.RfcSetString(container, cName, cValue, cLen, &errorInfo)
 	case C.RFCTYPE_NUM:
-		cValue, err = fillString(reflect.ValueOf(value).String())
+		var goVal string
+		goVal, err = numcValue(value)
+		if err != nil {
+			return valueError(err)
+		}
+		cValue, err = fillString(goVal)
 		//cLen := C.uint(len(reflect.ValueOf(value).String()))
 		cLen := C.uint(C.GoStrlenU((*C.SAP_UTF16)(cValue)))
 		rc = C.RfcSetNum(container, cName, (*C.RFC_NUM)(cValue), cLen, &errorInfo)
@@ -233,48 +259,149 @@ func fillVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 	//	rc = C.RfcSetString(container, cName, cValue, cLen, &errorInfo)
 	case C.RFCTYPE_FLOAT, C.RFCTYPE_BCD, C.RFCTYPE_DECF16, C.RFCTYPE_DECF34:
 		var goVal string
-		if reflect.TypeOf(value).Kind() == reflect.Float64 {
-			goVal = fmt.Sprintf("%g", reflect.ValueOf(value).Float())
+		if d, ok := value.(Decimal); ok {
+			goVal = d.String()
+			if cType != C.RFCTYPE_BCD {
+				goVal = d.decfloatString()
+			}
+		} else if number, ok := numberString(reflect.ValueOf(value)); ok {
+			goVal = number
 		} else {
 			goVal = reflect.ValueOf(value).String()
 		}
 		cValue, err = fillString(goVal)
 		cLen := C.uint(C.GoStrlenU((*C.SAP_UTF16)(cValue)))
 		rc = C.RfcSetString(container, cName, cValue, cLen, &errorInfo)
-	case C.RFCTYPE_INT1:
-		if reflect.TypeOf(value).Kind() == reflect.Int {
-			rc = C.RfcSetInt(container, cName, C.RFC_INT(reflect.ValueOf(value).Int()), &errorInfo)
-		} else {
-			rc = C.RfcSetInt(container, cName, C.RFC_INT(reflect.ValueOf(value).Uint()), &errorInfo)
+	case C.RFCTYPE_INT1, C.RFCTYPE_INT2, C.RFCTYPE_INT:
+		var i int64
+		i, err = integerValue(rfcType, value)
+		if err != nil {
+			return valueError(err)
 		}
-	case C.RFCTYPE_INT2, C.RFCTYPE_INT, C.RFCTYPE_INT8:
-		rc = C.RfcSetInt(container, cName, C.RFC_INT(reflect.ValueOf(value).Int()), &errorInfo)
+		rc = C.RfcSetInt(container, cName, C.RFC_INT(i), &errorInfo)
+	case C.RFCTYPE_INT8:
+		var i int64
+		i, err = integerValue(rfcType, value)
+		if err != nil {
+			return valueError(err)
+		}
+		rc = C.RfcSetInt8(container, cName, C.RFC_INT8(i), &errorInfo)
 	case C.RFCTYPE_DATE:
-		cValue, err = fillString(value.(time.Time).Format("20060102"))
+		var goVal string
+		switch d := value.(type) {
+		case Date:
+			goVal = d.abapDate()
+		case time.Time:
+			// zero time, like the unset field of a generated structure, is the initial value
+			goVal = "00000000"
+			if !d.IsZero() {
+				goVal = d.Format("20060102")
+			}
+		default:
+			goVal = reflect.ValueOf(value).String()
+			if goVal == "" {
+				goVal = "00000000"
+			}
+		}
+		cValue, err = fillString(goVal)
 		rc = C.RfcSetDate(container, cName, (*C.RFC_CHAR)(cValue), &errorInfo)
 	case C.RFCTYPE_TIME:
-		cValue, err = fillString(value.(time.Time).Format("150405"))
+		var goVal string
+		switch t := value.(type) {
+		case time.Duration:
+			goVal, err = abapTime(t)
+			if err != nil {
+				return valueError(err)
+			}
+		case time.Time:
+			goVal = t.Format("150405")
+		default:
+			goVal = reflect.ValueOf(value).String()
+			if goVal == "" {
+				goVal = "000000"
+			}
+		}
+		cValue, err = fillString(goVal)
 		rc = C.RfcSetTime(container, cName, (*C.RFC_CHAR)(cValue), &errorInfo)
 	case C.RFCTYPE_UTCLONG:
-		cValue, err = fillString(reflect.ValueOf(value).String())
+		var goVal string
+		if t, ok := value.(time.Time); ok {
+			goVal, err = formatUTCLong(t)
+			if err != nil {
+				return valueError(err)
+			}
+		} else {
+			goVal = reflect.ValueOf(value).String()
+		}
+		cValue, err = fillString(goVal)
 		//cLen := C.uint(len(reflect.ValueOf(value).String()))
 		cLen := C.uint(C.GoStrlenU((*C.SAP_UTF16)(cValue)))
 		rc = C.RfcSetString(container, cName, cValue, cLen, &errorInfo)
 	default:
-		var goName string
-		goName, err = wrapString(cName, true)
-		return rfcError(errorInfo, "Unknown RFC type %v when filling %v", cType, goName)
+		return errs.add(path, rfcError(errorInfo, "Unknown RFC type %v when filling %v", cType, path))
 	}
 	if rc != C.RFC_OK {
-		var goName string
-		goName, err = wrapString(cName, true)
-		err = rfcError(errorInfo, "Could not fill %v of type %v", goName, cType)
+		err = errs.add(path, rfcError(errorInfo, "Could not fill %v of type %v", path, cType))
 	}
 	return
 }
 
-func fillStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, value interface{}) (err error) {
+// utcLongFormat is the UTCLONG value format with 100ns precision
+const utcLongFormat = "2006-01-02T15:04:05.0000000"
+
+var (
+	utcLongMin = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	utcLongMax = time.Date(9999, 12, 31, 23, 59, 59, 999999900, time.UTC)
+)
+
+// formatUTCLong returns the UTCLONG value of the time, truncated to 100ns
+func formatUTCLong(t time.Time) (string, error) {
+	t = t.UTC().Truncate(100 * time.Nanosecond)
+	if t.Before(utcLongMin) || t.After(utcLongMax) {
+		return "", goRfcError(fmt.Sprintf("UTCLONG value %v out of range %v to %v", t, utcLongMin.Format(utcLongFormat), utcLongMax.Format(utcLongFormat)), nil)
+	}
+	return t.Format(utcLongFormat), nil
+}
+
+// parseUTCLong returns the UTCLONG value as UTC time, nil for the initial value
+func parseUTCLong(utc string) (interface{}, error) {
+	if len(utc) != len(utcLongFormat) {
+		return nil, goRfcError(fmt.Sprintf("Error parsing ABAP UTCLONG field %q", utc), nil)
+	}
+	if strings.HasPrefix(utc, "0000-00-00") {
+		return nil, nil
+	}
+	// the decimal separator may be comma
+	t, err := time.Parse(utcLongFormat, utc[:19]+"."+utc[20:])
+	if err != nil {
+		return nil, goRfcError("Error parsing ABAP UTCLONG field", err)
+	}
+	return t, nil
+}
+
+// indirect follows pointers and interfaces, returns false for nil values
+func indirect(value interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// fillStructure fills the structure or table line at path, stopping at the first field that can't be filled,
+// unless errs is set
+func fillStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, value interface{}, path string, errs *FillErrors) (err error) {
 	var errorInfo C.RFC_ERROR_INFO
+	value, ok := indirect(value)
+	if !ok {
+		return
+	}
 	s := reflect.ValueOf(value)
 
 	if s.Type().Kind() == reflect.Map {
@@ -285,51 +412,75 @@ func fillStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HA
 				for _, nameValue := range keys {
 					fieldName := nameValue.String()
 					fieldValue := s.MapIndex(nameValue).Interface()
-					err = fillStructureField(typeDesc, container, fieldName, fieldValue)
+					err = fillStructureField(typeDesc, container, fieldName, fieldValue, path+"."+fieldName, errs)
+					if err != nil {
+						return
+					}
 				}
 			} else {
-				return rfcError(errorInfo, "Could not fill structure passed as map with non-string keys")
+				return errs.add(path, rfcError(errorInfo, "Could not fill structure %v passed as map with non-string keys", path))
 			}
 		}
-	} else if s.Type().Kind() == reflect.Struct {
+	} else if s.Type().Kind() == reflect.Struct && !isScalarStruct(s.Type()) {
 		// Table passed as array of structures
-		for i := 0; i < s.NumField(); i++ {
-			fieldName := s.Type().Field(i).Name
-			fieldValue := s.Field(i).Interface()
-			err = fillStructureField(typeDesc, container, fieldName, fieldValue)
+		for _, field := range structFields(s.Type()) {
+			fieldValue, ok := fieldByIndex(s, field.index, false)
+			if !ok || (field.omitEmpty && fieldValue.IsZero()) {
+				continue
+			}
+			err = fillStructureField(typeDesc, container, field.name, fieldValue.Interface(), path+"."+field.name, errs)
+			if err != nil {
+				return
+			}
 		}
 	} else {
 		// Table passed as array of variables
-		err = fillStructureField(typeDesc, container, "", s.Interface())
+		err = fillStructureField(typeDesc, container, "", s.Interface(), path, errs)
 	}
 	return
 }
 
-func fillStructureField(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, fieldName string, fieldValue interface{}) (err error) {
+// isScalarStruct returns true for Go structures filled as ABAP variables
+func isScalarStruct(t reflect.Type) bool {
+	return t == reflect.TypeOf(time.Time{}) || t == reflect.TypeOf(Decimal{}) || t == reflect.TypeOf(Date{})
+}
+
+func fillStructureField(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, fieldName string, fieldValue interface{}, path string, errs *FillErrors) (err error) {
 	var rc C.RFC_RC
 	var errorInfo C.RFC_ERROR_INFO
 	var fieldDesc C.RFC_FIELD_DESC
 	cName, err := fillString(fieldName)
 	defer C.free(unsafe.Pointer(cName))
+	if err != nil {
+		return
+	}
 
 	rc = C.RfcGetFieldDescByName(typeDesc, cName, &fieldDesc, &errorInfo)
 	if rc != C.RFC_OK {
-		return rfcError(errorInfo, "Could not get field description for \"%v\"", fieldName)
+		return errs.add(path, rfcError(errorInfo, "Could not get field description for \"%v\"", path))
 	}
 
-	return fillVariable(fieldDesc._type, C.RFC_FUNCTION_HANDLE(container), (*C.SAP_UC)(&fieldDesc.name[0]), fieldValue, fieldDesc.typeDescHandle)
+	return fillVariable(fieldDesc._type, C.RFC_FUNCTION_HANDLE(container), (*C.SAP_UC)(&fieldDesc.name[0]), fieldValue, fieldDesc.typeDescHandle, fieldDesc.nucLength, fieldDesc.decimals, path, errs)
 }
 
-func fillTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, lines interface{}) (err error) {
+// fillTable fills the lines of the table at path, stopping at the first line that can't be filled, unless errs is set
+func fillTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, lines interface{}, path string, errs *FillErrors) (err error) {
 	var errorInfo C.RFC_ERROR_INFO
 	var lineHandle C.RFC_STRUCTURE_HANDLE
 	for i := 0; i < reflect.ValueOf(lines).Len(); i++ {
 		line := reflect.ValueOf(lines).Index(i)
 		lineHandle = C.RfcAppendNewRow(container, &errorInfo)
 		if lineHandle == nil {
-			return rfcError(errorInfo, "Could not append new row to table")
+			err = errs.add(path, rfcError(errorInfo, "Could not append new row to table %v", path))
+			if err != nil {
+				return
+			}
+			continue
+		}
+		err = fillStructure(typeDesc, lineHandle, line.Interface(), fmt.Sprintf("%v[%v]", path, i), errs)
+		if err != nil {
+			return
 		}
-		err = fillStructure(typeDesc, lineHandle, line.Interface())
 	}
 	return
 }
@@ -339,6 +490,34 @@ func fillTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, li
 //################################################################################
 //# Wrapper functions take C values and return Go values
 
+// wrapOptions control the Go values returned for ABAP values
+type wrapOptions struct {
+	// strip right strips CHAR, NUMC and STRING values
+	strip bool
+	// decimal returns BCD, DECF16 and DECF34 values as Decimal, else as string
+	decimal bool
+	// mapping selects alternative Go types, nil for default mapping
+	mapping *TypeMapping
+}
+
+// converter returns the converter registered for the DDIC type of the structure, nil if none
+func (options wrapOptions) converter(typeDesc C.RFC_TYPE_DESC_HANDLE) (converter Converter, err error) {
+	if options.mapping == nil || len(options.mapping.converters) == 0 {
+		return
+	}
+	var errorInfo C.RFC_ERROR_INFO
+	var typeName C.RFC_ABAP_NAME
+	rc := C.RfcGetTypeName(typeDesc, &typeName[0], &errorInfo)
+	if rc != C.RFC_OK {
+		return nil, rfcError(errorInfo, "Failed getting type name")
+	}
+	name, err := wrapString((*C.SAP_UC)(&typeName[0]), true)
+	if err != nil {
+		return
+	}
+	return options.mapping.converter(name), nil
+}
+
 func wrapString(sapuc *C.SAP_UC, strip bool) (string, error) {
 	//return nWrapString(sapuc, C.uint(C.strlenU((*C.ushort)(sapuc))), strip)
 	return nWrapString(sapuc, C.uint(C.GoStrlenU((*C.SAP_UTF16)(sapuc))), strip)
@@ -369,20 +548,11 @@ func nWrapString(sapuc *C.SAP_UC, sapucLength C.uint, strip bool) (string, error
 	return result, nil
 }
 
-type rfcSDKError struct {
-	Message       string
-	Code          string
-	Key           string
-	AbapMsgClass  string
-	AbapMsgType   string
-	AbapMsgNumber string
-	AbapMsgV1     string
-	AbapMsgV2     string
-	AbapMsgV3     string
-	AbapMsgV4     string
+func rfcError(errorInfo C.RFC_ERROR_INFO, format string, a ...interface{}) *RfcError {
+	return &RfcError{fmt.Sprintf(format, a...), wrapError(&errorInfo)}
 }
 
-func wrapError(errorInfo *C.RFC_ERROR_INFO) rfcSDKError {
+func wrapError(errorInfo *C.RFC_ERROR_INFO) RfcErrorInfo {
 	message, _ := wrapString(&errorInfo.message[0], true)
 	code, _ := wrapString(C.RfcGetRcAsString(errorInfo.code), true)
 	key, _ := wrapString(&errorInfo.key[0], true)
@@ -394,16 +564,9 @@ func wrapError(errorInfo *C.RFC_ERROR_INFO) rfcSDKError {
 	abapMsgV3, _ := wrapString(&errorInfo.abapMsgV3[0], true)
 	abapMsgV4, _ := wrapString(&errorInfo.abapMsgV4[0], true)
 
-	return rfcSDKError{message, code, key, abapMsgClass, abapMsgType, abapMsgNumber, abapMsgV1, abapMsgV2, abapMsgV3, abapMsgV4}
+	return RfcErrorInfo{message, code, ErrorGroup(errorInfo.group), key, abapMsgClass, abapMsgType, abapMsgNumber, abapMsgV1, abapMsgV2, abapMsgV3, abapMsgV4}
 }
 
-func (err rfcSDKError) String() string {
-	return fmt.Sprintf("rfcSDKError[%v, %v, %v, %v, %v, %v, %v, %v, %v, %v]", err.Message, err.Code, err.Key, err.AbapMsgClass, err.AbapMsgType, err.AbapMsgNumber, err.AbapMsgV1, err.AbapMsgV2, err.AbapMsgV3, err.AbapMsgV4)
-}
-
-// ConnectionAttributes returned by getConnectionInfo() method
-type ConnectionAttributes map[string]string
-
 func wrapConnectionAttributes(attributes C.RFC_ATTRIBUTES, strip bool) (connAttr ConnectionAttributes, err error) {
 	connAttr = make(map[string]string)
 
@@ -461,26 +624,6 @@ func wrapConnectionAttributes(attributes C.RFC_ATTRIBUTES, strip bool) (connAttr
 	return
 }
 
-// FieldDescription type
-type FieldDescription struct {
-	Name      string
-	FieldType string
-	NucLength uint
-	NucOffset uint
-	UcLength  uint
-	UcOffset  uint
-	Decimals  uint
-	TypeDesc  TypeDescription
-}
-
-// TypeDescription type
-type TypeDescription struct {
-	Name      string
-	NucLength uint
-	UcLength  uint
-	Fields    []FieldDescription
-}
-
 func wrapTypeDescription(typeDesc C.RFC_TYPE_DESC_HANDLE) (goTypeDesc TypeDescription, err error) {
 	var rc C.RFC_RC
 	var errorInfo C.RFC_ERROR_INFO
@@ -551,40 +694,6 @@ func wrapTypeDescription(typeDesc C.RFC_TYPE_DESC_HANDLE) (goTypeDesc TypeDescri
 	return
 }
 
-// ParameterDescription type
-type ParameterDescription struct {
-	Name          string
-	ParameterType string
-	Direction     string
-	NucLength     uint
-	UcLength      uint
-	Decimals      uint
-	DefaultValue  string
-	ParameterText string
-	Optional      bool
-	TypeDesc      TypeDescription
-	// ExtendedDescription interface{} //This field can be used by the application programmer (i.e. you) to store arbitrary extra information.
-}
-
-func (paramDesc ParameterDescription) String() string {
-	return fmt.Sprintf("paramDesc(name= %v, paramType= %v, dir= %v, nucLen= %v, ucLen= %v, dec= %v, defValue= %v, paramText= %v, optional= %v, typeDesc= %v)",
-		paramDesc.Name, paramDesc.ParameterType, paramDesc.Direction, paramDesc.NucLength, paramDesc.UcLength, paramDesc.Decimals, paramDesc.DefaultValue, paramDesc.ParameterText, paramDesc.Optional, paramDesc.TypeDesc)
-}
-
-// FunctionDescription type
-type FunctionDescription struct {
-	Name       string
-	Parameters []ParameterDescription
-}
-
-func (funcDesc FunctionDescription) String() (result string) {
-	result = fmt.Sprintf("FunctionDescription:\n Name: %v\n Parameters:\n", funcDesc.Name)
-	for i := 0; i < len(funcDesc.Parameters); i++ {
-		result += fmt.Sprintf("    %v\n", funcDesc.Parameters[i])
-	}
-	return
-}
-
 func wrapFunctionDescription(funcDesc C.RFC_FUNCTION_DESC_HANDLE) (goFuncDesc FunctionDescription, err error) {
 	var rc C.RFC_RC
 	var errorInfo C.RFC_ERROR_INFO
@@ -658,7 +767,15 @@ func wrapFunctionDescription(funcDesc C.RFC_FUNCTION_DESC_HANDLE) (goFuncDesc Fu
 	return
 }
 
-func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP_UC, cLen C.uint, typeDesc C.RFC_TYPE_DESC_HANDLE, strip bool) (result interface{}, err error) {
+func wrapDecimal(stringValue *C.SAP_UC, options wrapOptions) (result interface{}, err error) {
+	s, err := wrapString(stringValue, options.strip)
+	if err != nil || !options.decimal {
+		return s, err
+	}
+	return ParseDecimal(s)
+}
+
+func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP_UC, cLen C.uint, typeDesc C.RFC_TYPE_DESC_HANDLE, options wrapOptions) (result interface{}, err error) {
 	var rc C.RFC_RC
 	var errorInfo C.RFC_ERROR_INFO
 	var structure C.RFC_STRUCTURE_HANDLE
@@ -683,13 +800,13 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		if rc != C.RFC_OK {
 			return result, rfcError(errorInfo, "Failed getting structure")
 		}
-		return wrapStructure(typeDesc, structure, strip)
+		return wrapStructureValue(typeDesc, structure, options)
 	case C.RFCTYPE_TABLE:
 		rc = C.RfcGetTable(container, cName, &table, &errorInfo)
 		if rc != C.RFC_OK {
 			return result, rfcError(errorInfo, "Failed getting table")
 		}
-		return wrapTable(typeDesc, table, strip)
+		return wrapTable(typeDesc, table, options)
 	case C.RFCTYPE_CHAR:
 		charValue = (*C.RFC_CHAR)(C.GoMallocU(cLen))
 		defer C.free(unsafe.Pointer(charValue))
@@ -698,7 +815,14 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		if rc != C.RFC_OK {
 			return result, rfcError(errorInfo, "Failed getting chars")
 		}
-		return nWrapString((*C.SAP_UC)(charValue), cLen, strip)
+		value, err := nWrapString((*C.SAP_UC)(charValue), cLen, options.strip)
+		if err == nil && cLen == 1 && options.mapping != nil {
+			name, err := wrapString(cName, true)
+			if err == nil && options.mapping.char1AsBool(name) {
+				return mapChar1(name, value)
+			}
+		}
+		return value, err
 	case C.RFCTYPE_STRING:
 		rc = C.RfcGetStringLength(container, cName, &strLen, &errorInfo)
 		if rc != C.RFC_OK {
@@ -712,7 +836,7 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		if rc != C.RFC_OK {
 			return result, rfcError(errorInfo, "Failed getting string")
 		}
-		return wrapString(stringValue, strip)
+		return wrapString(stringValue, options.strip)
 	case C.RFCTYPE_NUM:
 		numValue = (*C.RFC_NUM)(C.GoMallocU(cLen))
 		defer C.free(unsafe.Pointer(numValue))
@@ -721,7 +845,11 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		if rc != C.RFC_OK {
 			return result, rfcError(errorInfo, "Failed getting num")
 		}
-		return nWrapString((*C.SAP_UC)(numValue), cLen, strip)
+		value, err := nWrapString((*C.SAP_UC)(numValue), cLen, options.strip)
+		if err == nil && options.mapping != nil && options.mapping.NumcAsInt64 {
+			return mapNumc(value, uint(cLen))
+		}
+		return value, err
 	case C.RFCTYPE_BYTE:
 		byteValue = (*C.SAP_RAW)(C.malloc(C.size_t(cLen)))
 		defer C.free(unsafe.Pointer(byteValue))
@@ -765,7 +893,7 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 			}
 		}
 		defer C.free(unsafe.Pointer(stringValue))
-		return wrapString(stringValue, strip)
+		return wrapDecimal(stringValue, options)
 	case C.RFCTYPE_DECF16, C.RFCTYPE_DECF34:
 		// An upper bound for the length of the _string representation_
 		// of the BCD is given by (2*cLen)-1 (each digit is encoded in 4bit,
@@ -790,7 +918,7 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 			}
 		}
 		defer C.free(unsafe.Pointer(stringValue))
-		return wrapString(stringValue, strip)
+		return wrapDecimal(stringValue, options)
 	case C.RFCTYPE_FLOAT:
 		rc = C.RfcGetFloat(container, cName, &floatValue, &errorInfo)
 		if rc != C.RFC_OK {
@@ -833,6 +961,9 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		if value == "00000000" || ' ' == value[1] || err != nil {
 			return
 		}
+		if options.mapping != nil && options.mapping.DateAsDate {
+			return ParseDate(value)
+		}
 		goDate, err := time.Parse("20060102", value)
 		if err != nil {
 			return nil, goRfcError("Error parsing ABAP RFC_DATE field", err)
@@ -847,6 +978,9 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 			return result, rfcError(errorInfo, "Failed getting TIME")
 		}
 		value, _ := nWrapString((*C.SAP_UC)(timeValue), 6, false)
+		if options.mapping != nil && options.mapping.TimeAsDuration {
+			return mapTime(value)
+		}
 		goTime, err := time.Parse("150405", value)
 		if err != nil {
 			return nil, goRfcError("Error parsing ABAP RFC_TIME field", err)
@@ -864,13 +998,13 @@ func wrapVariable(cType C.RFCTYPE, container C.RFC_FUNCTION_HANDLE, cName *C.SAP
 		if rc != C.RFC_OK {
 			return result, rfcError(errorInfo, "Failed getting UTCLONG")
 		}
-		utc, _ := nWrapString(stringValue, strLen, strip)
-		return utc[:19] + "." + utc[20:], err
+		utc, _ := nWrapString(stringValue, strLen, options.strip)
+		return parseUTCLong(utc)
 	}
 	return result, rfcError(errorInfo, "Unknown RFC type %d when wrapping variable", cType)
 }
 
-func wrapStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, strip bool) (result map[string]interface{}, err error) {
+func wrapStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, options wrapOptions) (result map[string]interface{}, err error) {
 	var errorInfo C.RFC_ERROR_INFO
 	var i, fieldCount C.uint
 	var fieldDesc C.RFC_FIELD_DESC
@@ -886,11 +1020,11 @@ func wrapStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HA
 			return result, rfcError(errorInfo, "Failed getting field description by index(%v)", i)
 		}
 		var fieldName string
-		fieldName, err = wrapString((*C.SAP_UC)(&fieldDesc.name[0]), strip)
+		fieldName, err = wrapString((*C.SAP_UC)(&fieldDesc.name[0]), options.strip)
 		if err != nil {
 			return
 		}
-		result[fieldName], err = wrapVariable(fieldDesc._type, C.RFC_FUNCTION_HANDLE(container), (*C.SAP_UC)(&fieldDesc.name[0]), fieldDesc.nucLength, fieldDesc.typeDescHandle, strip)
+		result[fieldName], err = wrapVariable(fieldDesc._type, C.RFC_FUNCTION_HANDLE(container), (*C.SAP_UC)(&fieldDesc.name[0]), fieldDesc.nucLength, fieldDesc.typeDescHandle, options)
 		if err != nil {
 			return
 		}
@@ -898,7 +1032,20 @@ func wrapStructure(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HA
 	return
 }
 
-func wrapTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, strip bool) (result []interface{}, err error) {
+// wrapStructureValue returns the structure fields, converted if a converter is registered for the DDIC type
+func wrapStructureValue(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_STRUCTURE_HANDLE, options wrapOptions) (result interface{}, err error) {
+	fields, err := wrapStructure(typeDesc, container, options)
+	if err != nil {
+		return
+	}
+	converter, err := options.converter(typeDesc)
+	if err != nil || converter == nil {
+		return fields, err
+	}
+	return converter(fields)
+}
+
+func wrapTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, options wrapOptions) (result []interface{}, err error) {
 	var errorInfo C.RFC_ERROR_INFO
 	var i, lines C.uint
 
@@ -906,6 +1053,10 @@ func wrapTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, st
 	if rc != C.RFC_OK {
 		return result, rfcError(errorInfo, "Failed getting row count")
 	}
+	converter, err := options.converter(typeDesc)
+	if err != nil {
+		return
+	}
 	result = make([]interface{}, lines, lines)
 	for i = 0; i < lines; i++ {
 		rc = C.RfcMoveTo(container, i, &errorInfo)
@@ -914,16 +1065,23 @@ func wrapTable(typeDesc C.RFC_TYPE_DESC_HANDLE, container C.RFC_TABLE_HANDLE, st
 		}
 		structHandle := C.RfcGetCurrentRow(container, &errorInfo)
 		var line map[string]interface{}
-		line, err = wrapStructure(typeDesc, structHandle, strip)
+		line, err = wrapStructure(typeDesc, structHandle, options)
 		if err != nil {
 			return
 		}
+		if converter != nil {
+			result[i], err = converter(line)
+			if err != nil {
+				return
+			}
+			continue
+		}
 		result[i] = line
 	}
 	return
 }
 
-func wrapResult(funcDesc C.RFC_FUNCTION_DESC_HANDLE, container C.RFC_FUNCTION_HANDLE, filterParameterDirection C.RFC_DIRECTION, strip bool) (result map[string]interface{}, err error) {
+func wrapResult(funcDesc C.RFC_FUNCTION_DESC_HANDLE, container C.RFC_FUNCTION_HANDLE, filterParameterDirection C.RFC_DIRECTION, options wrapOptions) (result map[string]interface{}, err error) {
 	var errorInfo C.RFC_ERROR_INFO
 	var i, paramCount C.uint
 	var paramDesc C.RFC_PARAMETER_DESC
@@ -941,11 +1099,11 @@ func wrapResult(funcDesc C.RFC_FUNCTION_DESC_HANDLE, container C.RFC_FUNCTION_HA
 		}
 		if paramDesc.direction != filterParameterDirection {
 			var fieldName string
-			fieldName, err = wrapString((*C.SAP_UC)(&paramDesc.name[0]), strip)
+			fieldName, err = wrapString((*C.SAP_UC)(&paramDesc.name[0]), options.strip)
 			if err != nil {
 				return
 			}
-			result[fieldName], err = wrapVariable(paramDesc._type, container, (*C.SAP_UC)(&paramDesc.name[0]), paramDesc.nucLength, paramDesc.typeDescHandle, strip)
+			result[fieldName], err = wrapVariable(paramDesc._type, container, (*C.SAP_UC)(&paramDesc.name[0]), paramDesc.nucLength, paramDesc.typeDescHandle, options)
 			if err != nil {
 				return
 			}
@@ -969,6 +1127,37 @@ func GetNWRFCLibVersion() (major, minor, patchlevel uint) {
 	return
 }
 
+// SetIniPath sets the directory in which the SAP NW RFC library looks for the sapnwrfc.ini file,
+// used by ConnectionFromDest. The default is the current working directory.
+func SetIniPath(dir string) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	pathName, err := fillString(dir)
+	defer C.free(unsafe.Pointer(pathName))
+	if err != nil {
+		return
+	}
+	rc := C.RfcSetIniPath(pathName, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not set ini path %v", dir)
+	}
+	iniPath.Lock()
+	iniPath.dir = dir
+	iniPath.Unlock()
+	return
+}
+
+// ReloadIniFile lets the SAP NW RFC library read the sapnwrfc.ini file again, after it has been changed.
+func ReloadIniFile() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	rc := C.RfcReloadIniFile(&errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not reload ini file")
+	}
+	return
+}
+
 //################################################################################
 //# CONNECTION                                                                   #
 //################################################################################
@@ -976,23 +1165,54 @@ func GetNWRFCLibVersion() (major, minor, patchlevel uint) {
 // Connection Parameters
 type ConnectionParameters map[string]string
 
+var _ Client = (*Connection)(nil)
+
 // Client Connection
 type Connection struct {
 	handle             C.RFC_CONNECTION_HANDLE
 	rstrip             bool
 	returnImportParams bool
+	returnDecimal      bool
+	strictValidation   bool
+	collectFillErrors  bool
+	typeMapping        *TypeMapping
 	alive              bool
-	paramCount         C.uint
 	connParams         []C.RFC_CONNECTION_PARAMETER
 	connectionParams   ConnectionParameters
-	// tHandle C.RFC_TRANSACTION_HANDLE
-	// active_transaction bool
-	// uHandle C.RFC_UNIT_HANDLE
-	// active_unit bool
+	credentialProvider CredentialProvider
+	retryPolicy        *RetryPolicy
+	sessionMu          sync.Mutex
+	session            *Session
+	sysID              string
+	language           string
+	tidStore           TIDStore
+	unitStore          UnitStore
 }
 
 func connectionFinalizer(conn *Connection) {
-	for _, connParam := range conn.connParams {
+	freeConnectionParameters(conn.connParams)
+}
+
+// fillConnectionParameters allocates memory for the connection parameters that has to be freed
+func fillConnectionParameters(connectionParams ConnectionParameters) (connParams []C.RFC_CONNECTION_PARAMETER, err error) {
+	connParams = make([]C.RFC_CONNECTION_PARAMETER, len(connectionParams), len(connectionParams))
+	i := 0
+	for name, value := range connectionParams {
+		connParams[i].name, err = fillString(name)
+		if err == nil {
+			connParams[i].value, err = fillString(value)
+		}
+		i++
+		if err != nil {
+			freeConnectionParameters(connParams[:i])
+			return nil, err
+		}
+	}
+	return
+}
+
+func freeConnectionParameters(connParams []C.RFC_CONNECTION_PARAMETER) {
+	for _, connParam := range connParams {
 		C.free(unsafe.Pointer(connParam.name))
 		C.free(unsafe.Pointer(connParam.value))
 	}
@@ -1001,6 +1221,18 @@ func connectionFinalizer(conn *Connection) {
 // ConnectionFromParams creates a new connection with the given connection parameters and tries to open it.
 // Returns the connection if successfull, otherwise nil.
 func ConnectionFromParams(connectionParams ConnectionParameters) (conn *Connection, err error) {
+	return ConnectionWithCredentials(connectionParams, nil)
+}
+
+// ConnectionWithCredentials creates a new connection with the given connection parameters and tries to open it,
+// with passwords, tokens or certificates supplied by the credential provider on each Open and Reopen.
+// Returns the connection if successfull, otherwise nil.
+func ConnectionWithCredentials(connectionParams ConnectionParameters, credentialProvider CredentialProvider) (conn *Connection, err error) {
+	err = connectionParams.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	conn = new(Connection)
 
 	conn.handle = nil
@@ -1009,17 +1241,13 @@ func ConnectionFromParams(connectionParams ConnectionParameters) (conn *Connecti
 	conn.alive = false
 
 	runtime.SetFinalizer(conn, connectionFinalizer)
-	conn.paramCount = C.uint(len(connectionParams))
 	conn.connectionParams = connectionParams
-	conn.connParams = make([]C.RFC_CONNECTION_PARAMETER, conn.paramCount, conn.paramCount)
-	i := 0
-	for name, value := range conn.connectionParams {
-		conn.connParams[i].name, err = fillString(name)
-		conn.connParams[i].value, err = fillString(value)
-		i++
-	}
-	if err != nil {
-		return nil, err
+	conn.credentialProvider = credentialProvider
+	if credentialProvider == nil {
+		conn.connParams, err = fillConnectionParameters(connectionParams)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = conn.Open()
@@ -1048,28 +1276,158 @@ func (conn *Connection) ReturnImportParams(returnImportParams bool) *Connection
 	return conn
 }
 
+// ReturnDecimal sets returnDecimal of the given connection to the passed parameter and returns the connection
+// BCD, DECF16 and DECF34 values are returned as Decimal if true, as string if false (default is false)
+func (conn *Connection) ReturnDecimal(returnDecimal bool) *Connection {
+	conn.returnDecimal = returnDecimal
+	return conn
+}
+
+// StrictValidation sets strictValidation of the given connection to the passed parameter and returns the connection
+// Parameters are checked by FunctionDescription.Validate before the call if true, including missing non-optional
+// importing parameters, lengths and value ranges. Otherwise only Go types are checked and values passed to the SDK
+// as before, like truncated CHAR values (default is false)
+func (conn *Connection) StrictValidation(strictValidation bool) *Connection {
+	conn.strictValidation = strictValidation
+	return conn
+}
+
+// CollectFillErrors sets collectFillErrors of the given connection to the passed parameter and returns the connection
+// All parameter and field values that can't be filled are returned in FillErrors if true,
+// the call is aborted at the first one if false (default is false)
+func (conn *Connection) CollectFillErrors(collectFillErrors bool) *Connection {
+	conn.collectFillErrors = collectFillErrors
+	return conn
+}
+
+func (conn *Connection) wrapOptions() wrapOptions {
+	return wrapOptions{strip: conn.rstrip, decimal: conn.returnDecimal, mapping: conn.typeMapping}
+}
+
+// TIDStore sets the store persisting transaction IDs of the given connection and returns the connection
+func (conn *Connection) TIDStore(tidStore TIDStore) *Connection {
+	conn.tidStore = tidStore
+	return conn
+}
+
+// UnitStore sets the store persisting bgRFC unit IDs of the given connection and returns the connection
+func (conn *Connection) UnitStore(unitStore UnitStore) *Connection {
+	conn.unitStore = unitStore
+	return conn
+}
+
+// String returns the connection parameters, with passwords and tokens redacted, and the connection state
+func (conn *Connection) String() string {
+	return fmt.Sprintf("Connection(%v, alive=%v)", conn.connectionParams, conn.alive)
+}
+
 // Alive returns true if the connection is open else returns false.
+// The open connection is checked by RfcIsConnectionHandleValid and marked as not alive
+// when closed by the SDK, after server-side timeout or network break.
 func (conn *Connection) Alive() bool {
+	if conn.alive {
+		conn.IsConnectionHandleValid()
+	}
 	return conn.alive
 }
 
+// IsConnectionHandleValid checks the connection handle by RfcIsConnectionHandleValid, without server roundtrip.
+// The connection with invalid handle is marked as not alive.
+func (conn *Connection) IsConnectionHandleValid() (valid bool, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	var isValid C.int
+	if conn.handle == nil {
+		conn.alive = false
+		return
+	}
+	rc := C.RfcIsConnectionHandleValid(conn.handle, &isValid, &errorInfo)
+	valid = rc == C.RFC_OK && isValid != 0
+	if !valid {
+		conn.alive = false
+	}
+	if rc != C.RFC_OK && errorInfo.code != C.RFC_INVALID_HANDLE {
+		err = rfcError(errorInfo, "Connection handle could not be checked")
+	}
+	return
+}
+
+// checkBroken marks the connection as not alive when the failed RFC call broke the connection
+func (conn *Connection) checkBroken(err error) {
+	if isConnectionBroken(err) {
+		conn.alive = false
+		return
+	}
+	conn.IsConnectionHandleValid()
+}
+
 // Close closes the connection and sets alive to false.
 func (conn *Connection) Close() (err error) {
+	if err = conn.checkPinned("Close"); err != nil {
+		return
+	}
+	return conn.closeConnection()
+}
+
+// closeConnection releases the connection handle, also of the broken connection not alive.
+// The close error is returned for the connection alive only.
+func (conn *Connection) closeConnection() (err error) {
 	var errorInfo C.RFC_ERROR_INFO
-	if conn.alive {
-		conn.alive = false
-		rc := C.RfcCloseConnection(conn.handle, &errorInfo)
-		if rc != C.RFC_OK {
-			return rfcError(errorInfo, "Connection could not be closed")
-		}
+	alive := conn.alive
+	conn.alive = false
+	if conn.handle == nil {
+		return
+	}
+	rc := C.RfcCloseConnection(conn.handle, &errorInfo)
+	conn.handle = nil
+	if rc != C.RFC_OK && alive {
+		return rfcError(errorInfo, "Connection could not be closed")
 	}
 	return
 }
 
 // Open opens the connection and sets alive to true.
+// With credential provider, the credentials are requested again and the logon retried once
+// when the logon failed and the provider returned changed credentials.
 func (conn *Connection) Open() (err error) {
+	if err = conn.checkPinned("Open"); err != nil {
+		return
+	}
+	return conn.openConnection()
+}
+
+func (conn *Connection) openConnection() (err error) {
+	if conn.credentialProvider == nil {
+		return conn.open(conn.connParams)
+	}
+
+	params, err := conn.credentials()
+	if err != nil {
+		return
+	}
+	err = conn.openWith(params)
+	if !errors.Is(err, LogonFailure) {
+		return
+	}
+	retryParams, retryErr := conn.credentials()
+	if retryErr != nil || reflect.DeepEqual(params, retryParams) {
+		return
+	}
+	return conn.openWith(retryParams)
+}
+
+// openWith opens the connection with the connection parameters freed after opening
+func (conn *Connection) openWith(params ConnectionParameters) (err error) {
+	connParams, err := fillConnectionParameters(params)
+	defer freeConnectionParameters(connParams)
+	if err != nil {
+		return
+	}
+	return conn.open(connParams)
+}
+
+func (conn *Connection) open(connParams []C.RFC_CONNECTION_PARAMETER) (err error) {
 	var errorInfo C.RFC_ERROR_INFO
-	conn.handle = C.RfcOpenConnection(&conn.connParams[0], conn.paramCount, &errorInfo)
+	conn.handle = C.RfcOpenConnection(&connParams[0], C.uint(len(connParams)), &errorInfo)
 	if errorInfo.code != C.RFC_OK {
 		return rfcError(errorInfo, "Connection could not be opened")
 	}
@@ -1079,32 +1437,51 @@ func (conn *Connection) Open() (err error) {
 
 // Reopen closes and opens the connection.
 func (conn *Connection) Reopen() (err error) {
-	err = conn.Close()
+	if err = conn.checkPinned("Reopen"); err != nil {
+		return
+	}
+	err = conn.closeConnection()
 	if err != nil {
 		return
 	}
-	err = conn.Open()
+	err = conn.openConnection()
 	return
 }
 
 // Ping pings the server which the client is connected to and does nothing with the error if one occurs.
 func (conn *Connection) Ping() (err error) {
+	if err = conn.checkPinned("Ping"); err != nil {
+		return
+	}
+	return conn.ping()
+}
+
+func (conn *Connection) ping() (err error) {
 	var errorInfo C.RFC_ERROR_INFO
 	if !conn.alive {
-		err = conn.Open()
+		err = conn.openConnection()
 		if err != nil {
 			return
 		}
 	}
 	rc := C.RfcPing(conn.handle, &errorInfo)
 	if rc != C.RFC_OK {
-		return rfcError(errorInfo, "Server could not be pinged")
+		err = rfcError(errorInfo, "Server could not be pinged")
+		conn.checkBroken(err)
+		return
 	}
 	return
 }
 
 // GetConnectionAttributes returns the wrapped connection attributes of the connection.
 func (conn *Connection) GetConnectionAttributes() (connAttr ConnectionAttributes, err error) {
+	if err = conn.checkPinned("GetConnectionAttributes"); err != nil {
+		return
+	}
+	return conn.getConnectionAttributes()
+}
+
+func (conn *Connection) getConnectionAttributes() (connAttr ConnectionAttributes, err error) {
 	var errorInfo C.RFC_ERROR_INFO
 	var attributes C.RFC_ATTRIBUTES
 
@@ -1116,103 +1493,150 @@ func (conn *Connection) GetConnectionAttributes() (connAttr ConnectionAttributes
 }
 
 // GetFunctionDescription returns the wrapped function description of the given function.
+// Function descriptions are cached by ABAP system ID and function name.
 func (conn *Connection) GetFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error) {
-	var errorInfo C.RFC_ERROR_INFO
-
-	funcName, err := fillString(goFuncName)
-	defer C.free(unsafe.Pointer(funcName))
-	if err != nil {
+	if err = conn.checkPinned("GetFunctionDescription"); err != nil {
 		return
 	}
+	return conn.getFunctionDescription(goFuncName)
+}
 
+func (conn *Connection) getFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error) {
 	if !conn.alive {
-		err = conn.Open()
+		err = conn.openConnection()
 		if err != nil {
 			return
 		}
 	}
 
-	funcDesc := C.RfcGetFunctionDesc(conn.handle, funcName, &errorInfo)
-	if funcDesc == nil {
-		return goFuncDesc, rfcError(errorInfo, "Could not get function description for \"%v\"", goFuncName)
+	_, goFuncDesc, err = conn.getFunctionDesc(goFuncName)
+	if err == nil {
+		conn.releaseFunctionDesc()
 	}
-
-	return wrapFunctionDescription(funcDesc)
+	return
 }
 
 // Call calls the given function with the given parameters and wraps the results returned.
 func (conn *Connection) Call(goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	return conn.CallContext(context.Background(), goFuncName, params)
+}
+
+// CallContext calls the given function with the given parameters and wraps the results returned.
+// When ctx is done before the function returns, the call is cancelled with RfcCancel, the connection
+// is marked as not alive and the returned error wraps ctx.Err().
+// With RetryPolicy, idempotent calls failed by broken connection are retried after reopening the connection.
+func (conn *Connection) CallContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	if conn.pinned() {
+		return nil, goRfcError("Connection is pinned by a session, use Session.Call()", nil)
+	}
+	return conn.callContext(ctx, goFuncName, params)
+}
+
+func (conn *Connection) callContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	result, err = conn.call(ctx, goFuncName, params)
+	if err != nil && conn.retryPolicy != nil {
+		return conn.retry(ctx, goFuncName, params, err)
+	}
+	return
+}
+
+func (conn *Connection) call(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
 	if !conn.alive {
 		return nil, goRfcError("Call() method requires an open connection", nil)
 	}
 
 	var errorInfo C.RFC_ERROR_INFO
 
-	funcName, err := fillString(goFuncName)
-	defer C.free(unsafe.Pointer(funcName))
+	funcDesc, funcCont, err := conn.createFunction(goFuncName, params)
 	if err != nil {
 		return
 	}
+	defer conn.releaseFunctionDesc()
+	defer C.RfcDestroyFunction(funcCont, nil)
 
-	if !conn.alive {
-		err = conn.Open()
-		if err != nil {
-			return
-		}
+	rc, err := conn.invoke(ctx, goFuncName, funcCont, &errorInfo)
+	if err != nil {
+		return
 	}
-
-	funcDesc := C.RfcGetFunctionDesc(conn.handle, funcName, &errorInfo)
-	if funcDesc == nil {
-		return result, rfcError(errorInfo, "Could not get function description for \"%v\"", funcName)
+	if rc != C.RFC_OK {
+		err = rfcError(errorInfo, "Could not invoke function \"%v\"", goFuncName)
+		conn.checkBroken(err)
+		return
 	}
 
-	funcCont := C.RfcCreateFunction(funcDesc, &errorInfo)
-	if funcCont == nil {
-		return result, rfcError(errorInfo, "Could not create function")
+	if conn.returnImportParams {
+		return wrapResult(funcDesc, funcCont, (C.RFC_DIRECTION)(0), conn.wrapOptions())
 	}
+	return wrapResult(funcDesc, funcCont, C.RFC_IMPORT, conn.wrapOptions())
+}
 
-	defer C.RfcDestroyFunction(funcCont, nil)
-
-	paramsValue := reflect.ValueOf(params)
-	if paramsValue.Type().Kind() == reflect.Map {
-		keys := paramsValue.MapKeys()
-		if len(keys) > 0 {
-			if keys[0].Kind() == reflect.String {
-				for _, nameValue := range keys {
-					fieldName := nameValue.String()
-					fieldValue := paramsValue.MapIndex(nameValue).Interface()
+// createFunction creates the function container filled with parameters, to be destroyed by the caller
+// before releaseFunctionDesc
+func (conn *Connection) createFunction(goFuncName string, params interface{}) (funcDesc C.RFC_FUNCTION_DESC_HANDLE, funcCont C.RFC_FUNCTION_HANDLE, err error) {
+	var errorInfo C.RFC_ERROR_INFO
 
-					err = fillFunctionParameter(funcDesc, funcCont, fieldName, fieldValue)
-					if err != nil {
-						return
-					}
-				}
-			} else {
-				return result, rfcError(errorInfo, "Could not fill parameters passed as map with non-string keys")
-			}
+	funcDesc, goFuncDesc, err := conn.getFunctionDesc(goFuncName)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			conn.releaseFunctionDesc()
 		}
-	} else if paramsValue.Type().Kind() == reflect.Struct {
-		for i := 0; i < paramsValue.NumField(); i++ {
-			fieldName := paramsValue.Type().Field(i).Name
-			fieldValue := paramsValue.Field(i).Interface()
-
-			err = fillFunctionParameter(funcDesc, funcCont, fieldName, fieldValue)
-			if err != nil {
-				return
-			}
+	}()
+	// strict validation reports also missing non-optional importing parameters, before anything is filled
+	if conn.strictValidation {
+		err = goFuncDesc.Validate(params)
+		if err != nil {
+			return
 		}
-	} else {
-		return result, rfcError(errorInfo, "Parameters can only be passed as types map[string]interface{} or go-structures")
 	}
 
-	rc := C.RfcInvoke(conn.handle, funcCont, &errorInfo)
+	funcCont = C.RfcCreateFunction(funcDesc, &errorInfo)
+	if funcCont == nil {
+		return funcDesc, funcCont, rfcError(errorInfo, "Could not create function")
+	}
 
-	if rc != C.RFC_OK {
-		return result, rfcError(errorInfo, "Could not invoke function \"%v\"", goFuncName)
+	var errs *FillErrors
+	if conn.collectFillErrors {
+		errs = &FillErrors{}
 	}
+	err = fillFunctionParameters(funcDesc, funcCont, params, errs)
+	if err == nil && errs != nil && len(*errs) > 0 {
+		err = goRfcError(fmt.Sprintf("Could not fill %v values of \"%v\"", len(*errs), goFuncName), *errs)
+	}
+	if err != nil {
+		C.RfcDestroyFunction(funcCont, nil)
+		return funcDesc, nil, err
+	}
+	return
+}
 
-	if conn.returnImportParams {
-		return wrapResult(funcDesc, funcCont, (C.RFC_DIRECTION)(0), conn.rstrip)
+// invoke runs RfcInvoke and cancels it with RfcCancel when ctx is done before the invocation returns.
+// The cancelled connection is closed by the SDK and therefore marked as not alive.
+func (conn *Connection) invoke(ctx context.Context, goFuncName string, funcCont C.RFC_FUNCTION_HANDLE, errorInfo *C.RFC_ERROR_INFO) (rc C.RFC_RC, err error) {
+	if ctx.Done() == nil {
+		return C.RfcInvoke(conn.handle, funcCont, errorInfo), nil
+	}
+	if ctx.Err() != nil {
+		return rc, goRfcError(fmt.Sprintf("Call of \"%v\" not started", goFuncName), ctx.Err())
+	}
+
+	handle := conn.handle
+	done := make(chan C.RFC_RC, 1)
+	go func() {
+		done <- C.RfcInvoke(handle, funcCont, errorInfo)
+	}()
+
+	select {
+	case rc = <-done:
+		return rc, nil
+	case <-ctx.Done():
+		var cancelInfo C.RFC_ERROR_INFO
+		C.RfcCancel(handle, &cancelInfo)
+		// wait for RfcInvoke to return, the function container is still in use
+		<-done
+		conn.alive = false
+		return rc, goRfcError(fmt.Sprintf("Call of \"%v\" cancelled", goFuncName), ctx.Err())
 	}
-	return wrapResult(funcDesc, funcCont, C.RFC_IMPORT, conn.rstrip)
 }
@@ -1,9 +1,12 @@
 package gorfc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -100,6 +103,33 @@ func TestReopen(t *testing.T) {
 	c.Close()
 }
 
+func TestAlive(t *testing.T) {
+	fmt.Println("Connection test: Alive")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	valid, err := c.IsConnectionHandleValid()
+	assert.Nil(t, err)
+	assert.True(t, valid)
+	assert.True(t, c.Alive())
+	// ABAP runtime error closes the connection
+	_, err = c.Call("RFC_RAISE_ERROR", map[string]interface{}{"METHOD": "0", "MESSAGETYPE": "X"})
+	assert.NotNil(t, err)
+	assert.False(t, c.Alive())
+	assert.Nil(t, c.Reopen())
+	assert.True(t, c.Alive())
+	c.Close()
+	assert.False(t, c.Alive())
+}
+
+func TestAliveWithoutHandle(t *testing.T) {
+	fmt.Println("Connection test: Alive without handle")
+	c := &Connection{alive: true}
+	valid, err := c.IsConnectionHandleValid()
+	assert.Nil(t, err)
+	assert.False(t, valid)
+	assert.False(t, c.Alive())
+}
+
 func TestConnectFromDest(t *testing.T) {
 	fmt.Println("Connection test: Destination")
 	assert.Greater(t, len(os.Getenv("RFC_INI")), 0)
@@ -152,10 +182,8 @@ func TestMissingAshostConnect(t *testing.T) {
 	c, err := ConnectionFromParams(a)
 	assert.Nil(t, c)
 	assert.NotNil(t, err)
-	assert.Equal(t, "Connection could not be opened", err.(*RfcError).Description)
-	assert.Equal(t, "Parameter ASHOST, GWHOST, MSHOST or PORT is missing.", err.(*RfcError).ErrorInfo.Message)
-	assert.Equal(t, "RFC_INVALID_PARAMETER", err.(*RfcError).ErrorInfo.Code)
-	assert.Equal(t, "RFC_INVALID_PARAMETER", err.(*RfcError).ErrorInfo.Key)
+	// validated before opening
+	assert.Equal(t, "Invalid connection parameters: ashost, mshost, wshost or dest required", err.(*GoRfcError).Description)
 }
 
 func TestWrongParameter(t *testing.T) {
@@ -184,6 +212,23 @@ func TestCallOverClosedConnection(t *testing.T) {
 	assert.Equal(t, "Call() method requires an open connection", err.(*GoRfcError).Description)
 }
 
+func TestCallContextCancel(t *testing.T) {
+	fmt.Println("Connection Error: CallContext() deadline exceeded")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := c.CallContext(ctx, "RFC_PING_AND_WAIT", map[string]interface{}{"SECONDS": 10})
+	assert.Nil(t, r)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Call of \"RFC_PING_AND_WAIT\" cancelled", err.(*GoRfcError).Description)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, c.Alive())
+	err = c.Open()
+	assert.Nil(t, err)
+	c.Close()
+}
+
 //
 // STFC Tests
 //
@@ -262,6 +307,43 @@ func TestTableRowAsStructure(t *testing.T) {
 	c.Close()
 }
 
+func TestTableRowAsTaggedStructure(t *testing.T) {
+	fmt.Println("STFC: Table rows as tagged structure")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	type chars struct {
+		Char1 string `rfc:"RFCCHAR1"`
+		Char2 string `rfc:"RFCCHAR2,omitempty"`
+	}
+	type importedStruct struct {
+		chars
+		Float    float64 `rfc:"RFCFLOAT"`
+		Int4     *int32  `rfc:"RFCINT4"`
+		Data1    string  `rfc:"RFCDATA1"`
+		Internal string  `rfc:"-"`
+	}
+	type parameter struct {
+		Import *importedStruct  `rfc:"IMPORTSTRUCT"`
+		Table  []importedStruct `rfc:"RFCTABLE,omitempty"`
+	}
+	int4 := int32(345)
+	importStruct := importedStruct{chars{"A", ""}, 4.23456789, &int4, "HELLÖ SÄP", "not sent"}
+	r, err := c.Call("STFC_STRUCTURE", parameter{Import: &importStruct})
+	assert.Nil(t, err)
+	echoStruct := r["ECHOSTRUCT"].(map[string]interface{})
+	assert.Equal(t, "A", echoStruct["RFCCHAR1"])
+	assert.Equal(t, "", echoStruct["RFCCHAR2"])
+	assert.Equal(t, importStruct.Float, echoStruct["RFCFLOAT"])
+	assert.Equal(t, int4, echoStruct["RFCINT4"])
+	assert.Equal(t, importStruct.Data1, echoStruct["RFCDATA1"])
+
+	importStruct.Int4 = nil
+	r, err = c.Call("STFC_STRUCTURE", &parameter{Import: &importStruct})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), r["ECHOSTRUCT"].(map[string]interface{})["RFCINT4"])
+	c.Close()
+}
+
 func TestTableRowAsMap(t *testing.T) {
 	fmt.Println("STFC: Table rows as maps")
 	c, err := ConnectionFromParams(abapSystem())
@@ -398,6 +480,46 @@ func TestErrorFunctionCall(t *testing.T) {
 	c.Close()
 }
 
+func TestErrorGroup(t *testing.T) {
+	fmt.Println("Error: error groups with errors.Is and errors.As")
+	var err error = &RfcError{"Could not invoke function", RfcErrorInfo{Code: "RFC_COMMUNICATION_FAILURE", Group: CommunicationFailure}}
+	assert.True(t, errors.Is(err, CommunicationFailure))
+	assert.False(t, errors.Is(err, LogonFailure))
+	assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", err), CommunicationFailure))
+
+	var commError CommunicationError
+	assert.True(t, errors.As(err, &commError))
+	assert.Equal(t, "RFC_COMMUNICATION_FAILURE", commError.ErrorInfo.Code)
+	var logonError LogonError
+	assert.False(t, errors.As(err, &logonError))
+
+	assert.Equal(t, "COMMUNICATION_FAILURE", CommunicationFailure.String())
+	assert.Equal(t, "ErrorGroup(42)", ErrorGroup(42).String())
+}
+
+func TestErrorGroupLogon(t *testing.T) {
+	fmt.Println("Error: logon failure error group")
+	a := abapSystem()
+	a["user"] = "@!n0user"
+	_, err := ConnectionFromParams(a)
+	assert.True(t, errors.Is(err, LogonFailure))
+	var logonError LogonError
+	assert.True(t, errors.As(err, &logonError))
+	assert.Equal(t, "RFC_LOGON_FAILURE", logonError.ErrorInfo.Key)
+}
+
+func TestErrorGroupAbapMessage(t *testing.T) {
+	fmt.Println("Error: ABAP message error group")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	_, err = c.Call("RFC_RAISE_ERROR", map[string]interface{}{"MESSAGETYPE": "E"})
+	var abapError AbapApplicationError
+	assert.True(t, errors.As(err, &abapError))
+	assert.Equal(t, AbapApplicationFailure, abapError.ErrorInfo.Group)
+	assert.Equal(t, "E", abapError.ErrorInfo.AbapMsgType)
+	c.Close()
+}
+
 func abapSystem() ConnectionParameters {
 	return ConnectionParameters{
 		"user":   "demo",
@@ -421,21 +543,67 @@ func TestUtcLong(t *testing.T) {
 	utctest := testutils.RFC_MATH["UTCLONG"].(map[string]string)["MIN"]
 	r, err := c.Call("ZDATATYPES", map[string]interface{}{"IV_UTCLONG": utctest})
 	assert.Nil(t, err)
-	assert.Equal(t, utctest, reflect.ValueOf(r["EV_UTCLONG"]).String())
+	assert.Equal(t, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), r["EV_UTCLONG"])
 
 	utctest = testutils.RFC_MATH["UTCLONG"].(map[string]string)["MAX"]
 	r, err = c.Call("ZDATATYPES", map[string]interface{}{"IV_UTCLONG": utctest})
 	assert.Nil(t, err)
-	assert.Equal(t, utctest, reflect.ValueOf(r["EV_UTCLONG"]).String())
+	assert.Equal(t, time.Date(9999, 12, 31, 23, 59, 59, 999999900, time.UTC), r["EV_UTCLONG"])
 
 	utctest = testutils.RFC_MATH["UTCLONG"].(map[string]string)["INITIAL"]
 	r, err = c.Call("ZDATATYPES", map[string]interface{}{"IV_UTCLONG": utctest})
 	assert.Nil(t, err)
-	assert.Equal(t, utctest, reflect.ValueOf(r["EV_UTCLONG"]).String())
+	assert.Nil(t, r["EV_UTCLONG"])
+
+	utc := time.Date(2024, 2, 29, 23, 59, 59, 123456700, time.UTC)
+	r, err = c.Call("ZDATATYPES", map[string]interface{}{"IV_UTCLONG": utc.In(time.FixedZone("CET", 3600))})
+	assert.Nil(t, err)
+	assert.Equal(t, utc, r["EV_UTCLONG"])
+
+	r, err = c.Call("ZDATATYPES", map[string]interface{}{"IV_UTCLONG": nil})
+	assert.Nil(t, err)
+	assert.Nil(t, r["EV_UTCLONG"])
+
+	_, err = c.Call("ZDATATYPES", map[string]interface{}{"IV_UTCLONG": time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assert.Equal(t, "Could not fill IV_UTCLONG", err.(*GoRfcError).Description)
+	assert.Equal(t, "UTCLONG value 10000-01-01 00:00:00 +0000 UTC out of range 0001-01-01T00:00:00.0000000 to 9999-12-31T23:59:59.9999999",
+		err.(*GoRfcError).GoError.(*GoRfcError).Description)
 
 	c.Close()
 }
 
+func TestUtcLongConversion(t *testing.T) {
+	fmt.Println("Datatypes: UTCLONG time conversion")
+	for _, utc := range []string{
+		testutils.RFC_MATH["UTCLONG"].(map[string]string)["MIN"],
+		testutils.RFC_MATH["UTCLONG"].(map[string]string)["MAX"],
+		"2024-02-29T12:30:45.1234567",
+	} {
+		value, err := parseUTCLong(utc)
+		assert.Nil(t, err)
+		formatted, err := formatUTCLong(value.(time.Time))
+		assert.Nil(t, err)
+		assert.Equal(t, utc, formatted)
+	}
+
+	value, err := parseUTCLong("2024-02-29T12:30:45,1234567")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 2, 29, 12, 30, 45, 123456700, time.UTC), value)
+	value, err = parseUTCLong(testutils.RFC_MATH["UTCLONG"].(map[string]string)["INITIAL"])
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	_, err = parseUTCLong("2024-02-30T12:30:45.1234567")
+	assert.NotNil(t, err)
+
+	formatted, err := formatUTCLong(time.Date(2024, 2, 29, 13, 30, 45, 123456789, time.FixedZone("CET", 3600)))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-02-29T12:30:45.1234567", formatted)
+	_, err = formatUTCLong(time.Date(0, 12, 31, 23, 59, 59, 0, time.UTC))
+	assert.NotNil(t, err)
+	_, err = formatUTCLong(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NotNil(t, err)
+}
+
 func TestIntMaxPositive(t *testing.T) {
 	fmt.Println("Datatypes: Integers max positive")
 	c, err := ConnectionFromDest("MME")
@@ -636,6 +804,50 @@ func TestNonArrayForArrayParam(t *testing.T) {
 		"OPTIONS":     "A string instead of an array",
 	}
 	_, err = c.Call("RFC_READ_TABLE", params)
-	assert.Equal(t, "GO string passed to ABAP TABLE parameter, expected GO array", err.(*GoRfcError).Description)
+	assert.Equal(t, "GO string passed to ABAP TABLE parameter OPTIONS, expected GO array", err.(*GoRfcError).Description)
+	c.Close()
+}
+
+func TestFillErrors(t *testing.T) {
+	fmt.Println("Datatypes: Fill errors collected")
+	var abort *FillErrors
+	err := goRfcError("Could not fill IT_ITEMS[0].MATNR", nil)
+	assert.Equal(t, err, abort.add("IT_ITEMS[0].MATNR", err))
+
+	errs := &FillErrors{}
+	assert.Nil(t, errs.add("IT_ITEMS[0].MATNR", err))
+	assert.Nil(t, errs.add("IT_ITEMS[3].MENGE", goRfcError("Could not fill IT_ITEMS[3].MENGE", nil)))
+	assert.Nil(t, errs.add("IT_ITEMS[4].MENGE", nil))
+	assert.Equal(t, 2, len(*errs))
+	assert.Equal(t, "IT_ITEMS[3].MENGE", (*errs)[1].Path)
+	assert.Equal(t, "IT_ITEMS[0].MATNR: GORFC error: Could not fill IT_ITEMS[0].MATNR; "+
+		"IT_ITEMS[3].MENGE: GORFC error: Could not fill IT_ITEMS[3].MENGE", errs.Error())
+	var goErr *GoRfcError
+	assert.True(t, errors.As((*errs)[0], &goErr))
+}
+
+func TestFillErrorsCall(t *testing.T) {
+	fmt.Println("Datatypes: Fill errors with path")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	params := map[string]interface{}{
+		"RFCTABLE": []interface{}{
+			map[string]interface{}{"RFCINT1": 1},
+			map[string]interface{}{"RFCINT1": "256"},
+			map[string]interface{}{"RFCCHAR4": 1234, "RFCDATE": 20240229},
+		},
+	}
+	_, err = c.Call("STFC_STRUCTURE", params)
+	assert.Equal(t, "Could not fill RFCTABLE[1].RFCINT1", err.(*GoRfcError).Description)
+
+	c.CollectFillErrors(true)
+	_, err = c.Call("STFC_STRUCTURE", params)
+	assert.Equal(t, "Could not fill 3 values of \"STFC_STRUCTURE\"", err.(*GoRfcError).Description)
+	var paths []string
+	for _, fillErr := range err.(*GoRfcError).GoError.(FillErrors) {
+		paths = append(paths, fillErr.Path)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{"RFCTABLE[1].RFCINT1", "RFCTABLE[2].RFCCHAR4", "RFCTABLE[2].RFCDATE"}, paths)
 	c.Close()
 }
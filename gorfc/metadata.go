@@ -0,0 +1,311 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+//################################################################################
+//# OFFLINE METADATA                                                             #
+//################################################################################
+//# Function descriptions exported to JSON files are loaded into the metadata
+//# cache without ABAP system, rebuilding the native function descriptions.
+
+var rfcTypes = map[string]C.RFCTYPE{
+	"RFCTYPE_CHAR":       C.RFCTYPE_CHAR,
+	"RFCTYPE_DATE":       C.RFCTYPE_DATE,
+	"RFCTYPE_BCD":        C.RFCTYPE_BCD,
+	"RFCTYPE_TIME":       C.RFCTYPE_TIME,
+	"RFCTYPE_BYTE":       C.RFCTYPE_BYTE,
+	"RFCTYPE_TABLE":      C.RFCTYPE_TABLE,
+	"RFCTYPE_NUM":        C.RFCTYPE_NUM,
+	"RFCTYPE_FLOAT":      C.RFCTYPE_FLOAT,
+	"RFCTYPE_INT":        C.RFCTYPE_INT,
+	"RFCTYPE_INT2":       C.RFCTYPE_INT2,
+	"RFCTYPE_INT1":       C.RFCTYPE_INT1,
+	"RFCTYPE_NULL":       C.RFCTYPE_NULL,
+	"RFCTYPE_ABAPOBJECT": C.RFCTYPE_ABAPOBJECT,
+	"RFCTYPE_STRUCTURE":  C.RFCTYPE_STRUCTURE,
+	"RFCTYPE_DECF16":     C.RFCTYPE_DECF16,
+	"RFCTYPE_DECF34":     C.RFCTYPE_DECF34,
+	"RFCTYPE_XMLDATA":    C.RFCTYPE_XMLDATA,
+	"RFCTYPE_STRING":     C.RFCTYPE_STRING,
+	"RFCTYPE_XSTRING":    C.RFCTYPE_XSTRING,
+	"RFCTYPE_INT8":       C.RFCTYPE_INT8,
+	"RFCTYPE_UTCLONG":    C.RFCTYPE_UTCLONG,
+	"RFCTYPE_UTCSECOND":  C.RFCTYPE_UTCSECOND,
+	"RFCTYPE_UTCMINUTE":  C.RFCTYPE_UTCMINUTE,
+	"RFCTYPE_DTDAY":      C.RFCTYPE_DTDAY,
+	"RFCTYPE_DTWEEK":     C.RFCTYPE_DTWEEK,
+	"RFCTYPE_DTMONTH":    C.RFCTYPE_DTMONTH,
+	"RFCTYPE_TSECOND":    C.RFCTYPE_TSECOND,
+	"RFCTYPE_TMINUTE":    C.RFCTYPE_TMINUTE,
+	"RFCTYPE_CDAY":       C.RFCTYPE_CDAY,
+}
+
+var rfcDirections = map[string]C.RFC_DIRECTION{
+	"RFC_IMPORT":   C.RFC_IMPORT,
+	"RFC_EXPORT":   C.RFC_EXPORT,
+	"RFC_CHANGING": C.RFC_CHANGING,
+	"RFC_TABLES":   C.RFC_TABLES,
+}
+
+// WriteFunctionDescriptions writes the function descriptions to the JSON file at path.
+func WriteFunctionDescriptions(path string, funcDescs ...FunctionDescription) (err error) {
+	if funcDescs == nil {
+		funcDescs = []FunctionDescription{}
+	}
+	return writeJSONFile(path, funcDescs)
+}
+
+// ReadFunctionDescriptions reads the function descriptions from the JSON file at path.
+func ReadFunctionDescriptions(path string) (funcDescs []FunctionDescription, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &funcDescs)
+	return
+}
+
+// ExportFunctionDescriptions reads the function descriptions from the ABAP system
+// and writes them to the JSON file at path.
+func (conn *Connection) ExportFunctionDescriptions(path string, goFuncNames ...string) (err error) {
+	funcDescs := make([]FunctionDescription, len(goFuncNames))
+	for i, goFuncName := range goFuncNames {
+		funcDescs[i], err = conn.GetFunctionDescription(goFuncName)
+		if err != nil {
+			return
+		}
+	}
+	return WriteFunctionDescriptions(path, funcDescs...)
+}
+
+// LoadFunctionDescriptions rebuilds the native function descriptions and adds them to the
+// metadata cache of the ABAP system sysID, replacing descriptions already cached.
+// Loaded descriptions are used by connections to sysID in all logon languages, without reading them
+// from the ABAP system.
+func LoadFunctionDescriptions(sysID string, funcDescs ...FunctionDescription) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	repositoryID, err := fillString(sysID)
+	defer C.free(unsafe.Pointer(repositoryID))
+	if err != nil {
+		return
+	}
+
+	for _, funcDesc := range funcDescs {
+		var handle C.RFC_FUNCTION_DESC_HANDLE
+		handle, err = createFunctionDesc(funcDesc)
+		if err != nil {
+			return
+		}
+
+		// the description replaced is removed, waiting for the running calls like InvalidateFunction
+		err = removeDescriptions(sysID, func() error {
+			for key := range metadataCache.functions {
+				if key.sysID == sysID && key.funcName == funcDesc.Name {
+					delete(metadataCache.functions, key)
+				}
+			}
+			if err := removeFunctionDesc(sysID, funcDesc.Name); err != nil {
+				return err
+			}
+			rc := C.RfcAddFunctionDesc(repositoryID, handle, &errorInfo)
+			if rc != C.RFC_OK {
+				return rfcError(errorInfo, "Could not add function description for \"%v\"", funcDesc.Name)
+			}
+			metadataCache.functions[functionKey{sysID, "", funcDesc.Name}] = cachedFunction{handle, funcDesc}
+			return nil
+		})
+		if err != nil {
+			C.RfcDestroyFunctionDesc(handle, nil)
+			return
+		}
+	}
+	return
+}
+
+// ValidateParameters checks the parameters by FunctionDescription.Validate against the cached function
+// description of the ABAP system sysID, then fills them into the function container created from it,
+// and returns the error if any parameter is invalid or can't be filled.
+func ValidateParameters(sysID string, goFuncName string, params interface{}) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	acquireRepository(sysID)
+	defer releaseRepository(sysID)
+	cached, ok := lookupFunction(sysID, "", goFuncName)
+	if !ok {
+		return goRfcError(fmt.Sprintf("No function description cached for \"%v\" of system %v", goFuncName, sysID), nil)
+	}
+
+	err = cached.desc.Validate(params)
+	if err != nil {
+		return
+	}
+
+	funcCont := C.RfcCreateFunction(cached.handle, &errorInfo)
+	if funcCont == nil {
+		return rfcError(errorInfo, "Could not create function \"%v\"", goFuncName)
+	}
+	defer C.RfcDestroyFunction(funcCont, nil)
+
+	return fillFunctionParameters(cached.handle, funcCont, params, nil)
+}
+
+// createFunctionDesc rebuilds the native function description
+func createFunctionDesc(goFuncDesc FunctionDescription) (funcDesc C.RFC_FUNCTION_DESC_HANDLE, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	funcName, err := fillString(goFuncDesc.Name)
+	defer C.free(unsafe.Pointer(funcName))
+	if err != nil {
+		return
+	}
+
+	funcDesc = C.RfcCreateFunctionDesc(funcName, &errorInfo)
+	if funcDesc == nil {
+		return nil, rfcError(errorInfo, "Could not create function description for \"%v\"", goFuncDesc.Name)
+	}
+
+	// type descriptions are shared by parameters and fields of the same type,
+	// attached ones are released together with the function or type description using them
+	typeDescs := make(map[string]C.RFC_TYPE_DESC_HANDLE)
+	attached := make(map[C.RFC_TYPE_DESC_HANDLE]bool)
+	defer func() {
+		if err != nil {
+			C.RfcDestroyFunctionDesc(funcDesc, nil)
+			funcDesc = nil
+			for _, typeDesc := range typeDescs {
+				if !attached[typeDesc] {
+					C.RfcDestroyTypeDesc(typeDesc, nil)
+				}
+			}
+		}
+	}()
+
+	for _, goParamDesc := range goFuncDesc.Parameters {
+		var paramDesc C.RFC_PARAMETER_DESC
+
+		err = fillBuffer(abapName(&paramDesc.name), goParamDesc.Name)
+		if err != nil {
+			return
+		}
+		paramType, ok := rfcTypes[goParamDesc.ParameterType]
+		if !ok {
+			return funcDesc, goRfcError(fmt.Sprintf("Unknown type %v of parameter \"%v\"", goParamDesc.ParameterType, goParamDesc.Name), nil)
+		}
+		direction, ok := rfcDirections[goParamDesc.Direction]
+		if !ok {
+			return funcDesc, goRfcError(fmt.Sprintf("Unknown direction %v of parameter \"%v\"", goParamDesc.Direction, goParamDesc.Name), nil)
+		}
+		paramDesc._type = paramType
+		paramDesc.direction = direction
+		paramDesc.nucLength = C.uint(goParamDesc.NucLength)
+		paramDesc.ucLength = C.uint(goParamDesc.UcLength)
+		paramDesc.decimals = C.uint(goParamDesc.Decimals)
+		if goParamDesc.Optional {
+			paramDesc.optional = 1
+		}
+		err = fillBuffer((*[len(paramDesc.defaultValue)]C.SAP_UC)(unsafe.Pointer(&paramDesc.defaultValue))[:], goParamDesc.DefaultValue)
+		if err != nil {
+			return
+		}
+		err = fillBuffer((*[len(paramDesc.parameterText)]C.SAP_UC)(unsafe.Pointer(&paramDesc.parameterText))[:], goParamDesc.ParameterText)
+		if err != nil {
+			return
+		}
+
+		if paramType == C.RFCTYPE_STRUCTURE || paramType == C.RFCTYPE_TABLE {
+			paramDesc.typeDescHandle, err = createTypeDesc(goParamDesc.TypeDesc, typeDescs, attached)
+			if err != nil {
+				return
+			}
+		}
+
+		rc := C.RfcAddParameter(funcDesc, &paramDesc, &errorInfo)
+		if rc != C.RFC_OK {
+			return funcDesc, rfcError(errorInfo, "Could not add parameter \"%v\" to function description \"%v\"", goParamDesc.Name, goFuncDesc.Name)
+		}
+		if paramDesc.typeDescHandle != nil {
+			attached[paramDesc.typeDescHandle] = true
+		}
+	}
+	return
+}
+
+// createTypeDesc rebuilds the native type description, with nested type descriptions created before
+func createTypeDesc(goTypeDesc TypeDescription, typeDescs map[string]C.RFC_TYPE_DESC_HANDLE, attached map[C.RFC_TYPE_DESC_HANDLE]bool) (typeDesc C.RFC_TYPE_DESC_HANDLE, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if typeDesc, ok := typeDescs[goTypeDesc.Name]; ok {
+		return typeDesc, nil
+	}
+
+	typeName, err := fillString(goTypeDesc.Name)
+	defer C.free(unsafe.Pointer(typeName))
+	if err != nil {
+		return
+	}
+
+	typeDesc = C.RfcCreateTypeDesc(typeName, &errorInfo)
+	if typeDesc == nil {
+		return nil, rfcError(errorInfo, "Could not create type description for \"%v\"", goTypeDesc.Name)
+	}
+	typeDescs[goTypeDesc.Name] = typeDesc
+
+	for _, goFieldDesc := range goTypeDesc.Fields {
+		var fieldDesc C.RFC_FIELD_DESC
+
+		err = fillBuffer(abapName(&fieldDesc.name), goFieldDesc.Name)
+		if err != nil {
+			return
+		}
+		fieldType, ok := rfcTypes[goFieldDesc.FieldType]
+		if !ok {
+			return typeDesc, goRfcError(fmt.Sprintf("Unknown type %v of field \"%v\"", goFieldDesc.FieldType, goFieldDesc.Name), nil)
+		}
+		fieldDesc._type = fieldType
+		fieldDesc.nucLength = C.uint(goFieldDesc.NucLength)
+		fieldDesc.nucOffset = C.uint(goFieldDesc.NucOffset)
+		fieldDesc.ucLength = C.uint(goFieldDesc.UcLength)
+		fieldDesc.ucOffset = C.uint(goFieldDesc.UcOffset)
+		fieldDesc.decimals = C.uint(goFieldDesc.Decimals)
+
+		if fieldType == C.RFCTYPE_STRUCTURE || fieldType == C.RFCTYPE_TABLE {
+			fieldDesc.typeDescHandle, err = createTypeDesc(goFieldDesc.TypeDesc, typeDescs, attached)
+			if err != nil {
+				return
+			}
+		}
+
+		rc := C.RfcAddTypeField(typeDesc, &fieldDesc, &errorInfo)
+		if rc != C.RFC_OK {
+			return typeDesc, rfcError(errorInfo, "Could not add field \"%v\" to type description \"%v\"", goFieldDesc.Name, goTypeDesc.Name)
+		}
+		if fieldDesc.typeDescHandle != nil {
+			attached[fieldDesc.typeDescHandle] = true
+		}
+	}
+
+	rc := C.RfcSetTypeLength(typeDesc, C.uint(goTypeDesc.NucLength), C.uint(goTypeDesc.UcLength), &errorInfo)
+	if rc != C.RFC_OK {
+		return typeDesc, rfcError(errorInfo, "Could not set length of type description \"%v\"", goTypeDesc.Name)
+	}
+	return
+}
+
+func abapName(name *C.RFC_ABAP_NAME) []C.SAP_UC {
+	return (*[len(C.RFC_ABAP_NAME{})]C.SAP_UC)(unsafe.Pointer(name))[:]
+}
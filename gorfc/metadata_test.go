@@ -0,0 +1,68 @@
+package gorfc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Offline Metadata Tests
+//
+
+func TestFunctionDescriptionsFile(t *testing.T) {
+	fmt.Println("Metadata: Function descriptions JSON file")
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	funcDesc := FunctionDescription{
+		Name: "STFC_STRUCTURE",
+		Parameters: []ParameterDescription{
+			{Name: "IMPORTSTRUCT", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_IMPORT", NucLength: 144, UcLength: 264,
+				TypeDesc: TypeDescription{Name: "RFCTEST", NucLength: 144, UcLength: 264, Fields: []FieldDescription{
+					{Name: "RFCFLOAT", FieldType: "RFCTYPE_FLOAT", NucLength: 8, UcLength: 8},
+					{Name: "RFCCHAR1", FieldType: "RFCTYPE_CHAR", NucLength: 1, NucOffset: 8, UcLength: 2, UcOffset: 8},
+				}}},
+			{Name: "RESPTEXT", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_EXPORT", NucLength: 255, UcLength: 510, Optional: true},
+		},
+	}
+	assert.Nil(t, WriteFunctionDescriptions(path, funcDesc))
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"parameterType": "RFCTYPE_STRUCTURE"`)
+
+	funcDescs, err := ReadFunctionDescriptions(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []FunctionDescription{funcDesc}, funcDescs)
+
+	_, err = ReadFunctionDescriptions(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
+
+func TestLoadFunctionDescriptions(t *testing.T) {
+	fmt.Println("Metadata: Load exported function descriptions")
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	assert.Nil(t, c.ExportFunctionDescriptions(path, "STFC_CONNECTION", "STFC_STRUCTURE"))
+	c.Close()
+
+	funcDescs, err := ReadFunctionDescriptions(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(funcDescs))
+	assert.Nil(t, LoadFunctionDescriptions("OFFLINE", funcDescs...))
+
+	assert.Nil(t, ValidateParameters("OFFLINE", "STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"}))
+	assert.Nil(t, ValidateParameters("OFFLINE", "STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCINT1": 1, "RFCCHAR4": "ABCD"},
+	}))
+	err = ValidateParameters("OFFLINE", "STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCDATE": "not a date"},
+	})
+	assert.NotNil(t, err)
+	err = ValidateParameters("OFFLINE", "BAPI_USER_GET_DETAIL", nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, ClearMetadataCache("OFFLINE"))
+}
@@ -0,0 +1,238 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//################################################################################
+//# CONNECTION PARAMETERS                                                        #
+//################################################################################
+
+// validParams are the client and server connection parameter names known by SAP NW RFC library
+var validParams = map[string]bool{
+	// destination and logon
+	"dest": true, "user": true, "passwd": true, "password": true, "client": true, "lang": true,
+	"alias_user": true, "mysapsso2": true, "getsso2": true, "x509cert": true, "extidata": true, "extidtype": true,
+	"password_change_enforced": true, "use_sapgui": true, "abap_debug": true, "codepage": true, "on_ccs_error": true,
+	// application server
+	"ashost": true, "sysnr": true, "gwhost": true, "gwserv": true, "saprouter": true,
+	// message server and logon group
+	"mshost": true, "msserv": true, "sysid": true, "r3name": true, "group": true, "logon_group_check_interval": true,
+	// WebSocket RFC
+	"wshost": true, "wsport": true, "tls_client_pse": true, "tls_client_certificate_logon": true,
+	"tls_trust_all": true, "tls_sapcrypto_lib": true, "proxy_host": true, "proxy_port": true,
+	"proxy_user": true, "proxy_passwd": true, "use_symbolic_names": true,
+	// SNC
+	"snc_mode": true, "snc_qop": true, "snc_myname": true, "snc_partnername": true, "snc_lib": true, "snc_sso": true,
+	// registered server
+	"program_id": true, "registration_count": true, "tpname": true, "tphost": true, "system_ids": true,
+	// connection options
+	"trace": true, "lcheck": true, "delta": true, "no_compression": true, "compression_type": true,
+	"serialization_format": true, "pcs": true, "max_reg_count": true, "saplogon_id": true, "cfit": true,
+}
+
+// secretParams are not shown by String, GoString and LogValue
+var secretParams = map[string]bool{
+	"passwd": true, "password": true, "proxy_passwd": true, "mysapsso2": true, "x509cert": true, "extidata": true,
+}
+
+// redacted replaces the values of secret parameters
+const redacted = "********"
+
+var (
+	sysnrPattern  = regexp.MustCompile(`^[0-9]{2}$`)
+	clientPattern = regexp.MustCompile(`^[0-9]{3}$`)
+)
+
+// Validate checks the connection parameter combinations: application server (ashost, sysnr),
+// message server (mshost, sysid or msserv), WebSocket RFC (wshost, wsport), gateway (tpname) or registered server
+// (program_id, gwhost, gwserv) and SNC parameters. With dest, missing parameters are read from sapnwrfc.ini.
+// Parameter names are case insensitive. Parameters not known by gorfc are reported by Warnings only,
+// the SAP NW RFC library may support more than listed here.
+func (params ConnectionParameters) Validate() error {
+	var problems []string
+	add := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+	params = params.lowerCase()
+	has := func(name string) bool {
+		return params[name] != ""
+	}
+
+	var hosts []string
+	for _, host := range []string{"ashost", "mshost", "wshost"} {
+		if has(host) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) > 1 {
+		add("only one of %v can be set", strings.Join(hosts, ", "))
+	}
+	if len(hosts) == 0 && !has("dest") && !has("program_id") && !has("tpname") {
+		add("ashost, mshost, wshost or dest required")
+	}
+
+	if has("ashost") && !has("sysnr") && !has("gwserv") {
+		add("ashost requires sysnr")
+	}
+	if has("sysnr") && !sysnrPattern.MatchString(params["sysnr"]) {
+		add("sysnr %v must be two digits", params["sysnr"])
+	}
+	if has("mshost") && !has("sysid") && !has("r3name") && !has("msserv") {
+		add("mshost requires sysid or msserv")
+	}
+	if has("wshost") && !has("wsport") {
+		add("wshost requires wsport")
+	}
+	if has("wsport") && !has("wshost") && !has("dest") {
+		add("wsport requires wshost")
+	}
+	if has("program_id") && !has("dest") && (!has("gwhost") || !has("gwserv")) {
+		add("program_id requires gwhost and gwserv")
+	}
+	if has("client") && !clientPattern.MatchString(params["client"]) {
+		add("client %v must be three digits", params["client"])
+	}
+	if has("passwd") && !has("user") && !has("dest") {
+		add("passwd requires user")
+	}
+
+	switch params["snc_mode"] {
+	case "", "0":
+		for _, name := range []string{"snc_partnername", "snc_qop", "snc_myname", "snc_sso"} {
+			if has(name) {
+				add("%v requires snc_mode=1", name)
+			}
+		}
+	case "1":
+		if !has("snc_partnername") && !has("dest") {
+			add("snc_mode=1 requires snc_partnername")
+		}
+		switch params["snc_qop"] {
+		case "", "1", "2", "3", "8", "9":
+		default:
+			add("snc_qop %v must be 1, 2, 3, 8 or 9", params["snc_qop"])
+		}
+	default:
+		add("snc_mode %v must be 0 or 1", params["snc_mode"])
+	}
+
+	if problems != nil {
+		return goRfcError(fmt.Sprintf("Invalid connection parameters: %v", strings.Join(problems, "; ")), nil)
+	}
+	return nil
+}
+
+// Warnings returns the problems not failing Validate: parameter names not known by gorfc, with the
+// similar known name if any, and parameters ignored by the SAP NW RFC library, like group without mshost.
+func (params ConnectionParameters) Warnings() (warnings []string) {
+	add := func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+	params = params.lowerCase()
+
+	for _, name := range params.names() {
+		if !validParams[name] {
+			if suggestion := similarParam(name); suggestion != "" {
+				add("unknown parameter %v, did you mean %v?", name, suggestion)
+			} else {
+				add("unknown parameter %v", name)
+			}
+		}
+	}
+	if params["group"] != "" && params["mshost"] == "" && params["dest"] == "" {
+		add("group is used with mshost only")
+	}
+	return
+}
+
+func (params ConnectionParameters) lowerCase() ConnectionParameters {
+	lowerCase := make(ConnectionParameters, len(params))
+	for name, value := range params {
+		lowerCase[strings.ToLower(name)] = value
+	}
+	return lowerCase
+}
+
+// String returns the connection parameters with passwords and tokens redacted
+func (params ConnectionParameters) String() string {
+	var pairs []string
+	for _, name := range params.names() {
+		pairs = append(pairs, name+":"+params.redact(name))
+	}
+	return "map[" + strings.Join(pairs, " ") + "]"
+}
+
+// GoString returns the connection parameters with passwords and tokens redacted, for %#v format
+func (params ConnectionParameters) GoString() string {
+	var pairs []string
+	for _, name := range params.names() {
+		pairs = append(pairs, fmt.Sprintf("%q:%q", name, params.redact(name)))
+	}
+	return "gorfc.ConnectionParameters{" + strings.Join(pairs, ", ") + "}"
+}
+
+func (params ConnectionParameters) redact(name string) string {
+	if secretParams[strings.ToLower(name)] {
+		return redacted
+	}
+	return params[name]
+}
+
+func (params ConnectionParameters) names() (names []string) {
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// similarParam returns the valid parameter name within edit distance 2, if any
+func similarParam(name string) (similar string) {
+	best := 3
+	for valid := range validParams {
+		if d := editDistance(name, valid); d < best || (d == best && valid < similar) {
+			best, similar = d, valid
+		}
+	}
+	if best > 2 {
+		return ""
+	}
+	return
+}
+
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
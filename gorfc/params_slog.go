@@ -0,0 +1,16 @@
+//go:build go1.21 && ((linux && cgo) || (amd64 && cgo) || (darwin && cgo))
+// +build go1.21
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import "log/slog"
+
+// LogValue logs the connection parameters with passwords and tokens redacted
+func (params ConnectionParameters) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(params))
+	for _, name := range params.names() {
+		attrs = append(attrs, slog.String(name, params.redact(name)))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,23 @@
+//go:build go1.21
+// +build go1.21
+
+package gorfc
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogValueParams(t *testing.T) {
+	fmt.Println("Connection parameters: Secrets redacted in log")
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("connect", "params", ConnectionParameters{"user": "demo", "passwd": "welcome"})
+	assert.Contains(t, buf.String(), "params.passwd=********")
+	assert.Contains(t, buf.String(), "params.user=demo")
+	assert.NotContains(t, buf.String(), "welcome")
+}
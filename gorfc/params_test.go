@@ -0,0 +1,80 @@
+package gorfc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Connection Parameters Tests
+//
+
+func TestValidateParams(t *testing.T) {
+	fmt.Println("Connection parameters: Valid combinations")
+	for _, params := range []ConnectionParameters{
+		abapSystem(),
+		abapServer(),
+		{"dest": "MME"},
+		{"dest": "MME", "user": "other", "passwd": "secret"},
+		{"USER": "demo", "PASSWD": "welcome", "ASHOST": "10.68.110.51", "SYSNR": "00"},
+		{"mshost": "ldciqm7", "sysid": "QM7", "group": "PUBLIC", "client": "002"},
+		{"wshost": "qm7.example.com", "wsport": "443", "tls_client_pse": "client.pse"},
+		{"ashost": "10.68.110.51", "sysnr": "00", "snc_mode": "1", "snc_partnername": "p:CN=QM7", "snc_qop": "9"},
+		{"ashost": "ldciqm7", "sysnr": "20", "group": "PUBLIC", "client": "002"},
+		{"gwhost": "10.68.110.51", "gwserv": "sapgw00", "tpname": "GORFC_TEST_SERVER"},
+		{"ashost": "h", "sysnr": "00", "xyzzy": "x"},
+	} {
+		assert.Nil(t, params.Validate(), params)
+	}
+}
+
+func TestValidateParamsErrors(t *testing.T) {
+	fmt.Println("Connection parameters: Invalid combinations")
+	for _, test := range []struct {
+		params  ConnectionParameters
+		message string
+	}{
+		{ConnectionParameters{"user": "demo"}, "ashost, mshost, wshost or dest required"},
+		{ConnectionParameters{"ashost": "h", "mshost": "m", "sysnr": "00", "sysid": "QM7"}, "only one of ashost, mshost can be set"},
+		{ConnectionParameters{"ashost": "h"}, "ashost requires sysnr"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "0"}, "sysnr 0 must be two digits"},
+		{ConnectionParameters{"mshost": "m"}, "mshost requires sysid or msserv"},
+		{ConnectionParameters{"wshost": "w"}, "wshost requires wsport"},
+		{ConnectionParameters{"program_id": "P", "gwhost": "g"}, "program_id requires gwhost and gwserv"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "00", "client": "20"}, "client 20 must be three digits"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "00", "passwd": "x"}, "passwd requires user"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "00", "snc_partnername": "p:CN=QM7"}, "snc_partnername requires snc_mode=1"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "00", "snc_mode": "1"}, "snc_mode=1 requires snc_partnername"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "00", "snc_mode": "1", "snc_partnername": "p", "snc_qop": "5"}, "snc_qop 5 must be 1, 2, 3, 8 or 9"},
+		{ConnectionParameters{"ashost": "h", "sysnr": "00", "snc_mode": "2"}, "snc_mode 2 must be 0 or 1"},
+	} {
+		err := test.params.Validate()
+		assert.Equal(t, "Invalid connection parameters: "+test.message, err.(*GoRfcError).Description)
+	}
+
+	err := ConnectionParameters{"ashost": "h", "sysnr": "0", "client": "1"}.Validate()
+	assert.Equal(t, "Invalid connection parameters: sysnr 0 must be two digits; client 1 must be three digits", err.(*GoRfcError).Description)
+}
+
+func TestParamsWarnings(t *testing.T) {
+	fmt.Println("Connection parameters: Warnings")
+	assert.Nil(t, abapSystem().Warnings())
+	assert.Nil(t, ConnectionParameters{"dest": "QM7", "group": "PUBLIC"}.Warnings())
+	assert.Equal(t, []string{
+		"unknown parameter pasword, did you mean password?",
+		"unknown parameter xyzzy",
+		"group is used with mshost only",
+	}, ConnectionParameters{"user": "demo", "ashost": "h", "sysnr": "00", "PASWORD": "x", "xyzzy": "x", "group": "PUBLIC"}.Warnings())
+}
+
+func TestRedactParams(t *testing.T) {
+	fmt.Println("Connection parameters: Secrets redacted")
+	params := ConnectionParameters{"user": "demo", "passwd": "welcome", "MYSAPSSO2": "token", "ashost": "h"}
+	assert.Equal(t, "map[MYSAPSSO2:******** ashost:h passwd:******** user:demo]", params.String())
+	assert.Equal(t, "map[MYSAPSSO2:******** ashost:h passwd:******** user:demo]", fmt.Sprintf("%v", params))
+	assert.NotContains(t, fmt.Sprintf("%#v", params), "welcome")
+	assert.NotContains(t, fmt.Sprintf("%+v", params), "token")
+	assert.Equal(t, "welcome", params["passwd"])
+}
@@ -0,0 +1,260 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//################################################################################
+//# CONNECTION POOL                                                              #
+//################################################################################
+
+// minEvictInterval limits the frequency of idle eviction runs for very short IdleTimeout
+const minEvictInterval = time.Millisecond
+
+// PoolOptions configure the sizing and idle eviction of a connection Pool
+type PoolOptions struct {
+	// MinConnections are opened when the pool is created and never evicted as idle
+	MinConnections int
+	// MaxConnections caps the number of open connections, 0 means no limit
+	MaxConnections int
+	// IdleTimeout after which idle connections are closed, 0 disables idle eviction
+	IdleTimeout time.Duration
+	// Credentials supply passwords, tokens or certificates when connections are opened, optional
+	Credentials CredentialProvider
+}
+
+// PoolStats reports the pool usage
+type PoolStats struct {
+	MaxConnections  int
+	OpenConnections int
+	InUse           int
+	Idle            int
+	WaitCount       int64
+	WaitDuration    time.Duration
+	IdleClosed      int64
+}
+
+type idleConnection struct {
+	conn  *Connection
+	since time.Time
+}
+
+// Pool hands out connections opened with the same connection parameters.
+// The Pool is safe for concurrent use, connections borrowed by Get are owned
+// by the caller until given back by Put.
+type Pool struct {
+	mu               sync.Mutex
+	connectionParams ConnectionParameters
+	options          PoolOptions
+	idle             []idleConnection
+	inUse            map[*Connection]struct{}
+	slots            chan struct{}
+	stop             chan struct{}
+	closed           bool
+	waitCount        int64
+	waitDuration     time.Duration
+	idleClosed       int64
+}
+
+// PoolFromParams creates a new connection pool and opens options.MinConnections connections.
+func PoolFromParams(connectionParams ConnectionParameters, options PoolOptions) (pool *Pool, err error) {
+	if options.MinConnections < 0 || options.MaxConnections < 0 {
+		return nil, goRfcError("Pool connections count can not be negative", nil)
+	}
+	if options.MaxConnections > 0 && options.MinConnections > options.MaxConnections {
+		return nil, goRfcError("Pool MinConnections can not exceed MaxConnections", nil)
+	}
+
+	pool = &Pool{
+		connectionParams: connectionParams,
+		options:          options,
+		inUse:            make(map[*Connection]struct{}),
+		stop:             make(chan struct{}),
+	}
+	if options.MaxConnections > 0 {
+		pool.slots = make(chan struct{}, options.MaxConnections)
+	}
+
+	for i := 0; i < options.MinConnections; i++ {
+		var conn *Connection
+		conn, err = ConnectionWithCredentials(connectionParams, options.Credentials)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.idle = append(pool.idle, idleConnection{conn, time.Now()})
+	}
+
+	if options.IdleTimeout > 0 {
+		go pool.evictIdle()
+	}
+	return
+}
+
+// Get returns an idle connection validated by Ping or opens a new one.
+// When MaxConnections are in use, Get waits until a connection is put back, the pool is closed or ctx is done.
+func (pool *Pool) Get(ctx context.Context) (conn *Connection, err error) {
+	if pool.slots != nil {
+		start := time.Now()
+		select {
+		case pool.slots <- struct{}{}:
+		default:
+			select {
+			case pool.slots <- struct{}{}:
+			case <-pool.stop:
+				return nil, goRfcError("Pool is closed", nil)
+			case <-ctx.Done():
+				return nil, goRfcError("Pool connection not available", ctx.Err())
+			}
+			pool.mu.Lock()
+			pool.waitCount++
+			pool.waitDuration += time.Since(start)
+			pool.mu.Unlock()
+		}
+	}
+
+	pool.mu.Lock()
+	if pool.closed {
+		pool.mu.Unlock()
+		pool.releaseSlot()
+		return nil, goRfcError("Pool is closed", nil)
+	}
+	for len(pool.idle) > 0 {
+		conn = pool.idle[len(pool.idle)-1].conn
+		pool.idle = pool.idle[:len(pool.idle)-1]
+		pool.mu.Unlock()
+
+		if err = conn.Ping(); err == nil {
+			pool.mu.Lock()
+			pool.inUse[conn] = struct{}{}
+			pool.mu.Unlock()
+			return
+		}
+		conn.Close()
+		pool.mu.Lock()
+	}
+	pool.mu.Unlock()
+
+	conn, err = ConnectionWithCredentials(pool.connectionParams, pool.options.Credentials)
+	if err != nil {
+		pool.releaseSlot()
+		return nil, err
+	}
+	pool.mu.Lock()
+	pool.inUse[conn] = struct{}{}
+	pool.mu.Unlock()
+	return
+}
+
+// Put gives the connection borrowed by Get back to the pool.
+// Connections which are not alive anymore are dropped.
+func (pool *Pool) Put(conn *Connection) (err error) {
+	pool.mu.Lock()
+	if _, ok := pool.inUse[conn]; !ok {
+		pool.mu.Unlock()
+		return goRfcError("Connection does not belong to the pool", nil)
+	}
+	delete(pool.inUse, conn)
+	closed := pool.closed
+	pool.mu.Unlock()
+
+	// Alive checks the connection handle in the SDK, not holding the pool lock
+	keep := !closed && conn.Alive()
+	if keep {
+		pool.mu.Lock()
+		if keep = !pool.closed; keep {
+			pool.idle = append(pool.idle, idleConnection{conn, time.Now()})
+		}
+		pool.mu.Unlock()
+	}
+	if !keep {
+		err = conn.Close()
+	}
+	pool.releaseSlot()
+	return
+}
+
+// Stats returns the current pool statistics.
+func (pool *Pool) Stats() PoolStats {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return PoolStats{
+		MaxConnections:  pool.options.MaxConnections,
+		OpenConnections: len(pool.idle) + len(pool.inUse),
+		InUse:           len(pool.inUse),
+		Idle:            len(pool.idle),
+		WaitCount:       pool.waitCount,
+		WaitDuration:    pool.waitDuration,
+		IdleClosed:      pool.idleClosed,
+	}
+}
+
+// Close closes idle connections, stops the idle eviction and wakes up Get calls waiting for a connection.
+// Connections in use are closed when put back.
+func (pool *Pool) Close() (err error) {
+	pool.mu.Lock()
+	if pool.closed {
+		pool.mu.Unlock()
+		return
+	}
+	pool.closed = true
+	idle := pool.idle
+	pool.idle = nil
+	pool.mu.Unlock()
+
+	close(pool.stop)
+	for _, ic := range idle {
+		if closeErr := ic.conn.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return
+}
+
+func (pool *Pool) releaseSlot() {
+	if pool.slots != nil {
+		<-pool.slots
+	}
+}
+
+// evictIdle periodically closes connections idle longer than IdleTimeout, keeping MinConnections open
+func (pool *Pool) evictIdle() {
+	interval := pool.options.IdleTimeout / 2
+	if interval < minEvictInterval {
+		interval = minEvictInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pool.stop:
+			return
+		case now := <-ticker.C:
+			var expired []*Connection
+			pool.mu.Lock()
+			keep := pool.idle[:0]
+			open := len(pool.idle) + len(pool.inUse)
+			// oldest idle connections are at the front
+			for _, ic := range pool.idle {
+				if open > pool.options.MinConnections && now.Sub(ic.since) > pool.options.IdleTimeout {
+					expired = append(expired, ic.conn)
+					open--
+				} else {
+					keep = append(keep, ic)
+				}
+			}
+			pool.idle = keep
+			pool.idleClosed += int64(len(expired))
+			pool.mu.Unlock()
+
+			for _, conn := range expired {
+				conn.Close()
+			}
+		}
+	}
+}
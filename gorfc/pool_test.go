@@ -0,0 +1,116 @@
+package gorfc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Pool Tests
+//
+
+func TestPoolGetPut(t *testing.T) {
+	fmt.Println("Pool test: Get and Put")
+	p, err := PoolFromParams(abapSystem(), PoolOptions{MinConnections: 1, MaxConnections: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, p.Stats().Idle)
+
+	c, err := p.Get(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, c.Alive())
+	assert.Equal(t, 1, p.Stats().InUse)
+	assert.Equal(t, 0, p.Stats().Idle)
+
+	r, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Hällö", r["ECHOTEXT"])
+
+	assert.Nil(t, p.Put(c))
+	assert.Equal(t, 0, p.Stats().InUse)
+	assert.Equal(t, 1, p.Stats().Idle)
+	assert.NotNil(t, p.Put(c))
+	p.Close()
+	assert.False(t, c.Alive())
+}
+
+func TestPoolMaxConnections(t *testing.T) {
+	fmt.Println("Pool test: MaxConnections")
+	p, err := PoolFromParams(abapSystem(), PoolOptions{MaxConnections: 2})
+	assert.Nil(t, err)
+
+	c1, err := p.Get(context.Background())
+	assert.Nil(t, err)
+	c2, err := p.Get(context.Background())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	c3, err := p.Get(ctx)
+	assert.Nil(t, c3)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c, err := p.Get(context.Background())
+		assert.Nil(t, err)
+		assert.Nil(t, p.Put(c))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, p.Put(c1))
+	wg.Wait()
+
+	stats := p.Stats()
+	assert.Equal(t, 2, stats.OpenConnections)
+	assert.Equal(t, int64(1), stats.WaitCount)
+	assert.Greater(t, int64(stats.WaitDuration), int64(0))
+
+	assert.Nil(t, p.Put(c2))
+	p.Close()
+}
+
+func TestPoolClose(t *testing.T) {
+	fmt.Println("Pool test: Close wakes up waiting Get")
+	p, err := PoolFromParams(abapSystem(), PoolOptions{MaxConnections: 1, IdleTimeout: time.Nanosecond})
+	assert.Nil(t, err)
+	// the only connection slot is taken
+	p.slots <- struct{}{}
+
+	done := make(chan error)
+	go func() {
+		_, err := p.Get(context.Background())
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, p.Close())
+	select {
+	case err = <-done:
+		assert.Equal(t, "Pool is closed", err.(*GoRfcError).Description)
+	case <-time.After(time.Second):
+		t.Error("Get not woken up by Close")
+	}
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	fmt.Println("Pool test: Idle connections eviction")
+	p, err := PoolFromParams(abapSystem(), PoolOptions{MinConnections: 1, IdleTimeout: 200 * time.Millisecond})
+	assert.Nil(t, err)
+
+	c1, _ := p.Get(context.Background())
+	c2, _ := p.Get(context.Background())
+	assert.Nil(t, p.Put(c1))
+	assert.Nil(t, p.Put(c2))
+	assert.Equal(t, 2, p.Stats().Idle)
+
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, 1, p.Stats().Idle)
+	assert.Equal(t, int64(1), p.Stats().IdleClosed)
+	p.Close()
+}
@@ -0,0 +1,535 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//################################################################################
+//# RECORD AND REPLAY                                                            #
+//################################################################################
+//# Calls recorded against the ABAP system are saved into golden files and
+//# replayed without ABAP system. Values are saved with their Go types.
+
+// Recording is the content of the golden file
+type Recording struct {
+	Attributes ConnectionAttributes  `json:"attributes,omitempty"`
+	Functions  []FunctionDescription `json:"functions,omitempty"`
+	Calls      []RecordedCall        `json:"calls"`
+}
+
+// RecordedCall holds the function call parameters and results, in typed JSON form
+type RecordedCall struct {
+	Name   string         `json:"name"`
+	Params interface{}    `json:"params"`
+	Result interface{}    `json:"result"`
+	Error  *RecordedError `json:"error,omitempty"`
+}
+
+// RecordedError holds the RfcError, GoRfcError or other error returned by the call
+type RecordedError struct {
+	Description string        `json:"description,omitempty"`
+	ErrorInfo   *RfcErrorInfo `json:"errorInfo,omitempty"`
+	GoError     string        `json:"goError,omitempty"`
+}
+
+func recordError(err error) *RecordedError {
+	var rfcErr *RfcError
+	var goErr *GoRfcError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rfcErr):
+		return &RecordedError{Description: rfcErr.Description, ErrorInfo: &rfcErr.ErrorInfo}
+	case errors.As(err, &goErr):
+		recorded := &RecordedError{Description: goErr.Description}
+		if goErr.GoError != nil {
+			recorded.GoError = goErr.GoError.Error()
+		}
+		return recorded
+	}
+	return &RecordedError{GoError: err.Error()}
+}
+
+func (recorded *RecordedError) replay() error {
+	switch {
+	case recorded == nil:
+		return nil
+	case recorded.ErrorInfo != nil:
+		return &RfcError{recorded.Description, *recorded.ErrorInfo}
+	case recorded.Description != "":
+		var goerror error
+		if recorded.GoError != "" {
+			goerror = errors.New(recorded.GoError)
+		}
+		return goRfcError(recorded.Description, goerror)
+	}
+	return errors.New(recorded.GoError)
+}
+
+// ReadRecording reads the golden file at path
+func ReadRecording(path string) (recording Recording, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &recording)
+	return
+}
+
+// WriteRecording writes the golden file at path
+func WriteRecording(path string, recording Recording) (err error) {
+	return writeJSONFile(path, recording)
+}
+
+// Recorder is the Client recording the calls of the wrapped client, to be saved into golden file
+type Recorder struct {
+	mu        sync.Mutex
+	client    Client
+	path      string
+	recording Recording
+}
+
+var _ Client = (*Recorder)(nil)
+
+// NewRecorder returns the Recorder of client calls, saved into the golden file at path on Close
+func NewRecorder(client Client, path string) *Recorder {
+	return &Recorder{client: client, path: path}
+}
+
+// Call calls the function with the wrapped client and records the call
+func (recorder *Recorder) Call(goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	return recorder.CallContext(context.Background(), goFuncName, params)
+}
+
+// CallContext calls the function with the wrapped client and records the call
+func (recorder *Recorder) CallContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	result, err = recorder.client.CallContext(ctx, goFuncName, params)
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.recording.Calls = append(recorder.recording.Calls, RecordedCall{
+		Name:   goFuncName,
+		Params: marshalValue(params),
+		Result: marshalValue(result),
+		Error:  recordError(err),
+	})
+	return
+}
+
+// Ping pings with the wrapped client
+func (recorder *Recorder) Ping() error {
+	return recorder.client.Ping()
+}
+
+// GetFunctionDescription returns the function description of the wrapped client and records it
+func (recorder *Recorder) GetFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error) {
+	goFuncDesc, err = recorder.client.GetFunctionDescription(goFuncName)
+	if err != nil {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for i, funcDesc := range recorder.recording.Functions {
+		if funcDesc.Name == goFuncName {
+			recorder.recording.Functions[i] = goFuncDesc
+			return
+		}
+	}
+	recorder.recording.Functions = append(recorder.recording.Functions, goFuncDesc)
+	return
+}
+
+// GetConnectionAttributes returns the connection attributes of the wrapped client and records them
+func (recorder *Recorder) GetConnectionAttributes() (connAttr ConnectionAttributes, err error) {
+	connAttr, err = recorder.client.GetConnectionAttributes()
+	if err != nil {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.recording.Attributes = connAttr
+	return
+}
+
+// Save writes the recorded calls into the golden file
+func (recorder *Recorder) Save() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return WriteRecording(recorder.path, recorder.recording)
+}
+
+// Close saves the recorded calls and closes the wrapped client
+func (recorder *Recorder) Close() (err error) {
+	err = recorder.Save()
+	if err != nil {
+		return
+	}
+	return recorder.client.Close()
+}
+
+// Matcher reports if the parameter or field at path, like "IT_ITEMS[3].MATNR",
+// is ignored when matching the call parameters with recorded parameters
+type Matcher func(path string, value interface{}) bool
+
+// IgnoreFields ignores parameters and fields with given names or paths
+func IgnoreFields(names ...string) Matcher {
+	return func(path string, value interface{}) bool {
+		name := path[strings.LastIndexAny(path, ".]")+1:]
+		for _, ignored := range names {
+			if ignored == path || ignored == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IgnoreTimes ignores date, time and timestamp values passed as time.Time, Date or time.Duration
+func IgnoreTimes() Matcher {
+	return func(path string, value interface{}) bool {
+		switch value.(type) {
+		case time.Time, Date, time.Duration:
+			return true
+		}
+		return false
+	}
+}
+
+// Replayer is the Client serving calls from the golden file, without ABAP system
+type Replayer struct {
+	mu        sync.Mutex
+	recording Recording
+	replayed  []bool
+	matchers  []Matcher
+	alive     bool
+}
+
+var _ Client = (*Replayer)(nil)
+
+// NewReplayer returns the Replayer of calls recorded in the golden file at path.
+// Parameters are matched with recorded parameters, except for fields ignored by matchers.
+func NewReplayer(path string, matchers ...Matcher) (replayer *Replayer, err error) {
+	recording, err := ReadRecording(path)
+	if err != nil {
+		return nil, goRfcError(fmt.Sprintf("Could not read recording %v", path), err)
+	}
+	return &Replayer{
+		recording: recording,
+		replayed:  make([]bool, len(recording.Calls)),
+		matchers:  matchers,
+		alive:     true,
+	}, nil
+}
+
+// Call returns the results of the first not yet replayed call matching function name and parameters
+func (replayer *Replayer) Call(goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	return replayer.CallContext(context.Background(), goFuncName, params)
+}
+
+// CallContext returns the results of the first not yet replayed call matching function name and parameters
+func (replayer *Replayer) CallContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	replayer.mu.Lock()
+	defer replayer.mu.Unlock()
+	if !replayer.alive {
+		return nil, goRfcError("Call() method requires an open connection", nil)
+	}
+	if ctx.Err() != nil {
+		return nil, goRfcError(fmt.Sprintf("Call of \"%v\" not started", goFuncName), ctx.Err())
+	}
+
+	goParams := replayer.ignoreFields(marshalValue(params), "")
+	for i, call := range replayer.recording.Calls {
+		if replayer.replayed[i] || call.Name != goFuncName {
+			continue
+		}
+		if !matchValues(goParams, replayer.ignoreFields(call.Params, "")) {
+			continue
+		}
+		replayer.replayed[i] = true
+		if call.Error != nil {
+			return nil, call.Error.replay()
+		}
+		value, err := unmarshalValue(call.Result)
+		if err != nil {
+			return nil, goRfcError(fmt.Sprintf("Could not replay result of \"%v\"", goFuncName), err)
+		}
+		result, _ = value.(map[string]interface{})
+		return result, nil
+	}
+	return nil, goRfcError(fmt.Sprintf("No recorded call of \"%v\" matching parameters", goFuncName), nil)
+}
+
+// Pending returns the recorded calls not yet replayed
+func (replayer *Replayer) Pending() (pending []RecordedCall) {
+	replayer.mu.Lock()
+	defer replayer.mu.Unlock()
+	for i, call := range replayer.recording.Calls {
+		if !replayer.replayed[i] {
+			pending = append(pending, call)
+		}
+	}
+	return
+}
+
+// Ping does nothing
+func (replayer *Replayer) Ping() error {
+	replayer.mu.Lock()
+	defer replayer.mu.Unlock()
+	replayer.alive = true
+	return nil
+}
+
+// GetFunctionDescription returns the recorded function description
+func (replayer *Replayer) GetFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error) {
+	for _, funcDesc := range replayer.recording.Functions {
+		if funcDesc.Name == goFuncName {
+			return funcDesc, nil
+		}
+	}
+	return goFuncDesc, goRfcError(fmt.Sprintf("No recorded function description of \"%v\"", goFuncName), nil)
+}
+
+// GetConnectionAttributes returns the recorded connection attributes
+func (replayer *Replayer) GetConnectionAttributes() (connAttr ConnectionAttributes, err error) {
+	if replayer.recording.Attributes == nil {
+		return nil, goRfcError("No recorded connection attributes", nil)
+	}
+	return replayer.recording.Attributes, nil
+}
+
+// Close closes the replayer
+func (replayer *Replayer) Close() error {
+	replayer.mu.Lock()
+	defer replayer.mu.Unlock()
+	replayer.alive = false
+	return nil
+}
+
+// matchValues reports if the typed JSON values are equal. Times are compared by time.Equal,
+// the location is not kept by RFC3339 format.
+func matchValues(value interface{}, recorded interface{}) bool {
+	switch v := value.(type) {
+	case []interface{}:
+		r, ok := recorded.([]interface{})
+		if !ok || len(v) != len(r) {
+			return false
+		}
+		for i := range v {
+			if !matchValues(v[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		r, ok := recorded.(map[string]interface{})
+		if !ok || len(v) != len(r) {
+			return false
+		}
+		if s, ok := v["time"].(string); ok && len(v) == 1 {
+			recordedTime, ok := r["time"].(string)
+			if !ok {
+				return false
+			}
+			t, err := time.Parse(time.RFC3339Nano, s)
+			rt, recordedErr := time.Parse(time.RFC3339Nano, recordedTime)
+			return err == nil && recordedErr == nil && t.Equal(rt)
+		}
+		for key, field := range v {
+			recordedField, ok := r[key]
+			if !ok || !matchValues(field, recordedField) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(value, recorded)
+}
+
+// ignoreFields removes the values ignored by matchers from the typed JSON value
+func (replayer *Replayer) ignoreFields(node interface{}, path string) interface{} {
+	typed, ok := node.(map[string]interface{})
+	if !ok || len(replayer.matchers) == 0 {
+		return node
+	}
+	if path != "" {
+		value, err := unmarshalValue(node)
+		if err == nil {
+			for _, matcher := range replayer.matchers {
+				if matcher(path, value) {
+					return nil
+				}
+			}
+		}
+	}
+	if fields, ok := typed["map"].(map[string]interface{}); ok {
+		result := make(map[string]interface{}, len(fields))
+		for name, field := range fields {
+			result[name] = replayer.ignoreFields(field, joinPath(path, name))
+		}
+		return map[string]interface{}{"map": result}
+	}
+	if lines, ok := typed["slice"].([]interface{}); ok {
+		result := make([]interface{}, len(lines))
+		for i, line := range lines {
+			result[i] = replayer.ignoreFields(line, fmt.Sprintf("%v[%d]", path, i))
+		}
+		return map[string]interface{}{"slice": result}
+	}
+	return node
+}
+
+// marshalValue converts the Go value into typed JSON value, like {"int32": "1"}.
+// Go structures are converted to maps, as passed to ABAP.
+func marshalValue(value interface{}) interface{} {
+	value, ok := indirect(value)
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case []byte:
+		return map[string]interface{}{"bytes": base64.StdEncoding.EncodeToString(v)}
+	case time.Time:
+		return map[string]interface{}{"time": v.Format(time.RFC3339Nano)}
+	case Decimal:
+		return map[string]interface{}{"decimal": v.String()}
+	case Date:
+		return map[string]interface{}{"date": v.String()}
+	case time.Duration:
+		return map[string]interface{}{"duration": v.String()}
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"string": rv.String()}
+	case reflect.Bool:
+		return map[string]interface{}{"bool": rv.Bool()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{rv.Kind().String(): strconv.FormatInt(rv.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{rv.Kind().String(): strconv.FormatUint(rv.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{rv.Kind().String(): strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())}
+	case reflect.Map:
+		fields := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			fields[fmt.Sprint(key.Interface())] = marshalValue(rv.MapIndex(key).Interface())
+		}
+		return map[string]interface{}{"map": fields}
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		for _, field := range structFields(rv.Type()) {
+			fieldValue, ok := fieldByIndex(rv, field.index, false)
+			if !ok || (field.omitEmpty && fieldValue.IsZero()) {
+				continue
+			}
+			fields[field.name] = marshalValue(fieldValue.Interface())
+		}
+		return map[string]interface{}{"map": fields}
+	case reflect.Slice, reflect.Array:
+		lines := make([]interface{}, rv.Len())
+		for i := range lines {
+			lines[i] = marshalValue(rv.Index(i).Interface())
+		}
+		return map[string]interface{}{"slice": lines}
+	}
+	return map[string]interface{}{"string": fmt.Sprint(value)}
+}
+
+// unmarshalValue converts the typed JSON value back into the Go value
+func unmarshalValue(node interface{}) (value interface{}, err error) {
+	typed, ok := node.(map[string]interface{})
+	if !ok || len(typed) != 1 {
+		if node == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid typed value %v", node)
+	}
+	for goType, v := range typed {
+		s, _ := v.(string)
+		switch goType {
+		case "string":
+			return s, nil
+		case "bool":
+			b, _ := v.(bool)
+			return b, nil
+		case "bytes":
+			return base64.StdEncoding.DecodeString(s)
+		case "time":
+			return time.Parse(time.RFC3339Nano, s)
+		case "decimal":
+			return ParseDecimal(s)
+		case "date":
+			return ParseDate(s)
+		case "duration":
+			return time.ParseDuration(s)
+		case "int":
+			i, err := strconv.ParseInt(s, 10, 0)
+			return int(i), err
+		case "int8":
+			i, err := strconv.ParseInt(s, 10, 8)
+			return int8(i), err
+		case "int16":
+			i, err := strconv.ParseInt(s, 10, 16)
+			return int16(i), err
+		case "int32":
+			i, err := strconv.ParseInt(s, 10, 32)
+			return int32(i), err
+		case "int64":
+			return strconv.ParseInt(s, 10, 64)
+		case "uint":
+			u, err := strconv.ParseUint(s, 10, 0)
+			return uint(u), err
+		case "uint8":
+			u, err := strconv.ParseUint(s, 10, 8)
+			return uint8(u), err
+		case "uint16":
+			u, err := strconv.ParseUint(s, 10, 16)
+			return uint16(u), err
+		case "uint32":
+			u, err := strconv.ParseUint(s, 10, 32)
+			return uint32(u), err
+		case "uint64":
+			return strconv.ParseUint(s, 10, 64)
+		case "float32":
+			f, err := strconv.ParseFloat(s, 32)
+			return float32(f), err
+		case "float64":
+			return strconv.ParseFloat(s, 64)
+		case "map":
+			fields, _ := v.(map[string]interface{})
+			result := make(map[string]interface{}, len(fields))
+			for name, field := range fields {
+				result[name], err = unmarshalValue(field)
+				if err != nil {
+					return
+				}
+			}
+			return result, nil
+		case "slice":
+			lines, _ := v.([]interface{})
+			result := make([]interface{}, len(lines))
+			for i, line := range lines {
+				result[i], err = unmarshalValue(line)
+				if err != nil {
+					return
+				}
+			}
+			return result, nil
+		}
+		err = fmt.Errorf("unknown type %v", goType)
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package gorfc
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Record and Replay Tests
+//
+
+func TestMarshalValue(t *testing.T) {
+	fmt.Println("Record: Typed JSON values")
+	type item struct {
+		MATNR string
+		QTY   int32
+		DATE  time.Time `rfc:"DELIV_DATE"`
+	}
+	date := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	value := map[string]interface{}{
+		"INT1":  uint8(255),
+		"INT8":  int64(-9223372036854775808),
+		"FLOAT": 1.7976931348623157e+308,
+		"BCD":   NewDecimal(12345, 2),
+		"HEX":   []byte{0xca, 0xfe},
+		"FLAG":  true,
+		"ITEMS": []item{{"M-01", 3, date}},
+		"NULL":  nil,
+	}
+	result, err := unmarshalValue(marshalValue(value))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"INT1":  uint8(255),
+		"INT8":  int64(-9223372036854775808),
+		"FLOAT": 1.7976931348623157e+308,
+		"BCD":   NewDecimal(12345, 2),
+		"HEX":   []byte{0xca, 0xfe},
+		"FLAG":  true,
+		"ITEMS": []interface{}{map[string]interface{}{"MATNR": "M-01", "QTY": int32(3), "DELIV_DATE": date}},
+		"NULL":  nil,
+	}, result)
+}
+
+func TestRecordReplay(t *testing.T) {
+	fmt.Println("Record: Record and replay calls")
+	path := filepath.Join(t.TempDir(), "golden.json")
+
+	recorder := NewRecorder(fakeSystem(), path)
+	_, err := recorder.GetConnectionAttributes()
+	assert.Nil(t, err)
+	_, err = recorder.GetFunctionDescription("STFC_CONNECTION")
+	assert.Nil(t, err)
+	recorded, err := recorder.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hello"})
+	assert.Nil(t, err)
+	_, err = recorder.Call("RFC_RAISE_ERROR", map[string]interface{}{"MESSAGETYPE": "X", "TIMESTAMP": time.Now()})
+	assert.NotNil(t, err)
+	assert.Nil(t, recorder.Close())
+
+	replayer, err := NewReplayer(path, IgnoreTimes())
+	assert.Nil(t, err)
+	var c Client = replayer
+
+	attributes, err := c.GetConnectionAttributes()
+	assert.Nil(t, err)
+	assert.Equal(t, "FAK", attributes["sysId"])
+	d, err := c.GetFunctionDescription("STFC_CONNECTION")
+	assert.Nil(t, err)
+	assert.Equal(t, "STFC_CONNECTION", d.Name)
+
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Other"})
+	assert.NotNil(t, err)
+	r, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hello"})
+	assert.Nil(t, err)
+	assert.Equal(t, recorded, r)
+
+	_, err = c.Call("RFC_RAISE_ERROR", map[string]interface{}{"MESSAGETYPE": "X", "TIMESTAMP": time.Now()})
+	var abapError AbapApplicationError
+	assert.True(t, errors.As(err, &abapError))
+	assert.Equal(t, "RAISE_EXCEPTION", abapError.ErrorInfo.Key)
+	assert.Empty(t, replayer.Pending())
+
+	// each recorded call is replayed once
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hello"})
+	assert.NotNil(t, err)
+	assert.Nil(t, c.Close())
+}
+
+func TestReplayIgnoreFields(t *testing.T) {
+	fmt.Println("Record: Ignore volatile fields")
+	path := filepath.Join(t.TempDir(), "golden.json")
+	assert.Nil(t, WriteRecording(path, Recording{Calls: []RecordedCall{{
+		Name:   "STFC_STRUCTURE",
+		Params: marshalValue(map[string]interface{}{"IMPORTSTRUCT": map[string]interface{}{"RFCCHAR4": "ABCD", "RFCDATA1": "20240229"}}),
+		Result: marshalValue(map[string]interface{}{"RESPTEXT": "OK"}),
+	}}}))
+
+	replayer, err := NewReplayer(path, IgnoreFields("RFCDATA1"))
+	assert.Nil(t, err)
+	r, err := replayer.Call("STFC_STRUCTURE", map[string]interface{}{"IMPORTSTRUCT": map[string]interface{}{"RFCCHAR4": "ABCD", "RFCDATA1": "20261017"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", r["RESPTEXT"])
+}
+
+func TestReplayTimes(t *testing.T) {
+	fmt.Println("Record: Ignore and match times")
+	ignore := IgnoreTimes()
+	assert.True(t, ignore("DATE", time.Now()))
+	assert.True(t, ignore("DATE", Date{2024, time.February, 29}))
+	assert.True(t, ignore("TIME", 90*time.Minute))
+	assert.False(t, ignore("TEXT", "20240229"))
+
+	path := filepath.Join(t.TempDir(), "golden.json")
+	timestamp := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+	assert.Nil(t, WriteRecording(path, Recording{Calls: []RecordedCall{{
+		Name:   "RFC_RAISE_ERROR",
+		Params: marshalValue(map[string]interface{}{"TIMESTAMP": timestamp, "DATE": Date{2024, time.February, 29}}),
+		Result: marshalValue(map[string]interface{}{}),
+	}}}))
+
+	// the same instant in another location matches
+	replayer, err := NewReplayer(path)
+	assert.Nil(t, err)
+	_, err = replayer.Call("RFC_RAISE_ERROR", map[string]interface{}{
+		"TIMESTAMP": timestamp.In(time.FixedZone("CET", 3600)), "DATE": Date{2024, time.February, 29},
+	})
+	assert.Nil(t, err)
+
+	replayer, err = NewReplayer(path, IgnoreTimes())
+	assert.Nil(t, err)
+	_, err = replayer.Call("RFC_RAISE_ERROR", map[string]interface{}{"TIMESTAMP": time.Now(), "DATE": Date{2026, time.October, 17}})
+	assert.Nil(t, err)
+}
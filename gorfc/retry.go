@@ -0,0 +1,119 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+//################################################################################
+//# RETRY                                                                        #
+//################################################################################
+
+// Default backoff of the RetryPolicy
+const (
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy configures the reconnect and retry of calls failed with RFC_COMMUNICATION_FAILURE or RFC_CLOSED.
+// Only the functions marked as Idempotent are retried, because the failed call may have been executed
+// in ABAP system before the connection broke.
+type RetryPolicy struct {
+	// MaxAttempts of the call, including the first one, 1 or less means no retry
+	MaxAttempts int
+	// InitialBackoff before the first retry, DefaultInitialBackoff if 0
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff, DefaultMaxBackoff if 0
+	MaxBackoff time.Duration
+	// Multiplier of the backoff after each retry, 2 if 0
+	Multiplier float64
+	// Jitter randomly reduces the backoff by up to the given fraction, from 0 to 1
+	Jitter float64
+	// Idempotent are the names of functions safe to call more than once
+	Idempotent []string
+	// OnRetry is called before each retry with the attempt to come, backoff and error of the failed attempt, optional
+	OnRetry func(goFuncName string, attempt int, backoff time.Duration, err error)
+}
+
+// RetryPolicy sets the retry policy of the given connection and returns the connection, nil disables retries
+func (conn *Connection) RetryPolicy(retryPolicy *RetryPolicy) *Connection {
+	conn.retryPolicy = retryPolicy
+	return conn
+}
+
+// retry reopens the connection and calls the function again, while the policy allows.
+// Returns the result and error of the last attempt.
+func (conn *Connection) retry(ctx context.Context, goFuncName string, params interface{}, err error) (result map[string]interface{}, _ error) {
+	policy := conn.retryPolicy
+	for attempt := 2; policy.retryable(goFuncName, attempt, err); attempt++ {
+		backoff := policy.backoff(attempt)
+		if policy.OnRetry != nil {
+			policy.OnRetry(goFuncName, attempt, backoff, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, goRfcError(fmt.Sprintf("Retry of \"%v\" cancelled", goFuncName), ctx.Err())
+		}
+
+		// the broken handle is released, the close error is expected
+		conn.closeConnection()
+		if err = conn.openConnection(); err != nil {
+			continue
+		}
+		result, err = conn.call(ctx, goFuncName, params)
+	}
+	return result, err
+}
+
+// retryable returns true if the attempt is allowed after err
+func (policy *RetryPolicy) retryable(goFuncName string, attempt int, err error) bool {
+	if policy == nil || err == nil || attempt > policy.MaxAttempts || !isConnectionBroken(err) {
+		return false
+	}
+	for _, name := range policy.Idempotent {
+		if strings.EqualFold(name, goFuncName) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait time before the attempt, with jitter
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	initial, max, multiplier := policy.InitialBackoff, policy.MaxBackoff, policy.Multiplier
+	if initial == 0 {
+		initial = DefaultInitialBackoff
+	}
+	if max == 0 {
+		max = DefaultMaxBackoff
+	}
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	backoff := math.Min(float64(initial)*math.Pow(multiplier, float64(attempt-2)), float64(max))
+	if policy.Jitter > 0 {
+		backoff -= backoff * math.Min(policy.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(backoff)
+}
+
+// isConnectionBroken returns true for RFC_COMMUNICATION_FAILURE and RFC_CLOSED errors
+func isConnectionBroken(err error) bool {
+	var rfcErr *RfcError
+	if !errors.As(err, &rfcErr) {
+		return false
+	}
+	return rfcErr.ErrorInfo.Code == "RFC_COMMUNICATION_FAILURE" || rfcErr.ErrorInfo.Code == "RFC_CLOSED"
+}
@@ -0,0 +1,78 @@
+package gorfc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Retry Tests
+//
+
+func TestRetryable(t *testing.T) {
+	fmt.Println("Retry: Retryable errors")
+	policy := &RetryPolicy{MaxAttempts: 3, Idempotent: []string{"STFC_CONNECTION"}}
+	broken := &RfcError{"Could not invoke function", RfcErrorInfo{Code: "RFC_COMMUNICATION_FAILURE", Group: CommunicationFailure}}
+	closed := &RfcError{"Could not invoke function", RfcErrorInfo{Code: "RFC_CLOSED", Group: CommunicationFailure}}
+	abapError := NewAbapException("USER_NOT_FOUND", "User not found")
+
+	assert.True(t, policy.retryable("STFC_CONNECTION", 2, broken))
+	assert.True(t, policy.retryable("stfc_connection", 3, closed))
+	assert.True(t, policy.retryable("STFC_CONNECTION", 2, fmt.Errorf("wrapped: %w", broken)))
+	assert.False(t, policy.retryable("STFC_CONNECTION", 4, broken))
+	assert.False(t, policy.retryable("BAPI_USER_CREATE1", 2, broken))
+	assert.False(t, policy.retryable("STFC_CONNECTION", 2, abapError))
+	assert.False(t, policy.retryable("STFC_CONNECTION", 2, goRfcError("Call of \"STFC_CONNECTION\" cancelled", context.Canceled)))
+	assert.False(t, policy.retryable("STFC_CONNECTION", 2, nil))
+	assert.False(t, (*RetryPolicy)(nil).retryable("STFC_CONNECTION", 2, broken))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	fmt.Println("Retry: Exponential backoff")
+	policy := &RetryPolicy{MaxAttempts: 10}
+	assert.Equal(t, DefaultInitialBackoff, policy.backoff(2))
+	assert.Equal(t, 2*DefaultInitialBackoff, policy.backoff(3))
+	assert.Equal(t, DefaultMaxBackoff, policy.backoff(10))
+
+	policy = &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 3}
+	assert.Equal(t, time.Second, policy.backoff(2))
+	assert.Equal(t, 3*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(3)
+		assert.True(t, backoff > 1500*time.Millisecond && backoff <= 3*time.Second, backoff)
+	}
+}
+
+func TestRetryCall(t *testing.T) {
+	fmt.Println("Retry: Call without failure")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	retries := 0
+	c.RetryPolicy(&RetryPolicy{
+		MaxAttempts: 3,
+		Idempotent:  []string{"STFC_CONNECTION"},
+		OnRetry: func(goFuncName string, attempt int, backoff time.Duration, err error) {
+			retries++
+		},
+	})
+	r, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "retry"})
+	assert.Nil(t, err)
+	assert.Equal(t, "retry", r["ECHOTEXT"])
+	_, err = c.Call("RFC_RAISE_ERROR", map[string]interface{}{"METHOD": "0", "MESSAGETYPE": "A"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, retries)
+
+	// the handle of the broken connection is released before reopening
+	c.alive = false
+	assert.Nil(t, c.closeConnection())
+	assert.Nil(t, c.handle)
+	assert.Nil(t, c.openConnection())
+	c.Close()
+}
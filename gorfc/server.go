@@ -0,0 +1,306 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+
+extern RFC_RC goServerFunction(RFC_CONNECTION_HANDLE rfcHandle, RFC_FUNCTION_HANDLE funcHandle, RFC_ERROR_INFO* errorInfo);
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+//################################################################################
+//# SERVER                                                                       #
+//################################################################################
+
+// ServerFunction handles the ABAP call of a function module.
+// The params hold the IMPORT, CHANGING and TABLES parameters sent by ABAP,
+// the result returned fills the EXPORT, CHANGING and TABLES parameters sent back.
+// An AbapApplicationError returned raises the ABAP exception ErrorInfo.Key or, if
+// ErrorInfo.AbapMsgClass is set, the ABAP message. Other errors returned are raised
+// in ABAP as SYSTEM_FAILURE with the error message.
+type ServerFunction func(params map[string]interface{}) (result map[string]interface{}, err error)
+
+// server functions are installed in the SAP NW RFC library for the whole process and dispatched by
+// function name, for all servers of the process
+var serverFunctions = struct {
+	sync.RWMutex
+	handlers map[string]ServerFunction
+}{handlers: make(map[string]ServerFunction)}
+
+// listenTimeout in seconds, after which the server checks for shutdown
+const listenTimeout = 1
+
+// Server registers at the gateway and handles ABAP calls with Go server functions.
+// Server functions are shared by all servers of the process: the function installed last
+// for a function module name handles the ABAP calls of that function module on every server.
+type Server struct {
+	mu           sync.Mutex
+	serverParams ConnectionParameters
+	client       *Connection
+	handle       C.RFC_CONNECTION_HANDLE
+	connParams   []C.RFC_CONNECTION_PARAMETER
+	errorHandler func(error)
+	stop         chan struct{}
+	done         chan struct{}
+	running      bool
+}
+
+// ServerFromParams creates a new server, registering with the serverParams at the gateway.
+// The clientParams open the connection to the ABAP system, from which server functions metadata are read.
+func ServerFromParams(serverParams ConnectionParameters, clientParams ConnectionParameters) (server *Server, err error) {
+	err = serverParams.Validate()
+	if err != nil {
+		return
+	}
+	client, err := ConnectionFromParams(clientParams)
+	if err != nil {
+		return
+	}
+	server = &Server{serverParams: serverParams, client: client}
+	server.connParams, err = fillConnectionParameters(serverParams)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	runtime.SetFinalizer(server, serverFinalizer)
+	return
+}
+
+func serverFinalizer(server *Server) {
+	freeConnectionParameters(server.connParams)
+}
+
+// OnError sets the handler called with errors of the background listener and returns the server
+func (server *Server) OnError(errorHandler func(error)) *Server {
+	server.errorHandler = errorHandler
+	return server
+}
+
+// AddFunction installs the Go server function for the ABAP function module goFuncName,
+// replacing the server function installed for goFuncName by any server of the process.
+func (server *Server) AddFunction(goFuncName string, handler ServerFunction) (err error) {
+	if !server.client.Alive() {
+		err = server.client.Open()
+		if err != nil {
+			return
+		}
+	}
+
+	// the installed function description stays in use by the server, not counted as in use by calls
+	funcDesc, _, err := server.client.getFunctionDesc(goFuncName)
+	if err != nil {
+		return
+	}
+	server.client.releaseFunctionDesc()
+	return installServerFunction(goFuncName, funcDesc, handler)
+}
+
+// AddFunctionDescription installs the Go server function for the function description,
+// read for example from JSON file, without reading it from the ABAP system.
+func (server *Server) AddFunctionDescription(goFuncDesc FunctionDescription, handler ServerFunction) (err error) {
+	funcDesc, err := createFunctionDesc(goFuncDesc)
+	if err != nil {
+		return
+	}
+	err = installServerFunction(goFuncDesc.Name, funcDesc, handler)
+	if err != nil {
+		C.RfcDestroyFunctionDesc(funcDesc, nil)
+	}
+	return
+}
+
+func installServerFunction(goFuncName string, funcDesc C.RFC_FUNCTION_DESC_HANDLE, handler ServerFunction) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	serverFunctions.Lock()
+	serverFunctions.handlers[goFuncName] = handler
+	serverFunctions.Unlock()
+
+	rc := C.RfcInstallServerFunction(nil, funcDesc, C.RFC_SERVER_FUNCTION(C.goServerFunction), &errorInfo)
+	if rc != C.RFC_OK {
+		serverFunctions.Lock()
+		delete(serverFunctions.handlers, goFuncName)
+		serverFunctions.Unlock()
+		return rfcError(errorInfo, "Could not install server function \"%v\"", goFuncName)
+	}
+	return
+}
+
+// Start registers the server at the gateway and dispatches ABAP calls in the background.
+func (server *Server) Start() (err error) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.running {
+		return goRfcError("Server is already running", nil)
+	}
+	err = server.register()
+	if err != nil {
+		return
+	}
+	server.running = true
+	server.stop = make(chan struct{})
+	server.done = make(chan struct{})
+	go server.serve()
+	return
+}
+
+// Shutdown stops accepting ABAP calls and waits until the call in progress is completed or ctx is done.
+func (server *Server) Shutdown(ctx context.Context) (err error) {
+	server.mu.Lock()
+	if !server.running {
+		server.mu.Unlock()
+		return
+	}
+	server.running = false
+	close(server.stop)
+	server.mu.Unlock()
+
+	select {
+	case <-server.done:
+	case <-ctx.Done():
+		return goRfcError("Server shutdown not completed", ctx.Err())
+	}
+	return server.client.Close()
+}
+
+func (server *Server) register() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	server.handle = C.RfcRegisterServer(&server.connParams[0], C.uint(len(server.connParams)), &errorInfo)
+	if server.handle == nil {
+		return rfcError(errorInfo, "Server could not be registered")
+	}
+	return
+}
+
+func (server *Server) reportError(err error) {
+	if server.errorHandler != nil && err != nil {
+		server.errorHandler(err)
+	}
+}
+
+func (server *Server) serve() {
+	var errorInfo C.RFC_ERROR_INFO
+	defer close(server.done)
+	for {
+		select {
+		case <-server.stop:
+			C.RfcCloseConnection(server.handle, &errorInfo)
+			return
+		default:
+		}
+
+		rc := C.RfcListenAndDispatch(server.handle, listenTimeout, &errorInfo)
+		switch rc {
+		case C.RFC_OK, C.RFC_RETRY, C.RFC_ABAP_EXCEPTION, C.RFC_ABAP_MESSAGE, C.RFC_EXTERNAL_FAILURE:
+			// call handled, timeout or error returned by the server function, the connection is still open
+			continue
+		}
+
+		// connection closed, register again
+		server.reportError(rfcError(errorInfo, "Server connection closed"))
+		C.RfcCloseConnection(server.handle, &errorInfo)
+		for {
+			err := server.register()
+			if err == nil {
+				break
+			}
+			server.reportError(err)
+			select {
+			case <-server.stop:
+				return
+			case <-time.After(listenTimeout * time.Second):
+			}
+		}
+	}
+}
+
+//export goServerFunction
+func goServerFunction(rfcHandle C.RFC_CONNECTION_HANDLE, funcHandle C.RFC_FUNCTION_HANDLE, errorInfo *C.RFC_ERROR_INFO) (rc C.RFC_RC) {
+	var funcName C.RFC_ABAP_NAME
+
+	// a panic must not unwind into the SAP NW RFC library, it is raised in ABAP as SYSTEM_FAILURE
+	defer func() {
+		if r := recover(); r != nil {
+			rc = serverFunctionError(errorInfo, goRfcError(fmt.Sprintf("Server function panic: %v", r), nil))
+		}
+	}()
+
+	funcDesc := C.RfcDescribeFunction(funcHandle, errorInfo)
+	if funcDesc == nil {
+		return errorInfo.code
+	}
+	rc = C.RfcGetFunctionName(funcDesc, &funcName[0], errorInfo)
+	if rc != C.RFC_OK {
+		return rc
+	}
+	goFuncName, err := wrapString((*C.SAP_UC)(&funcName[0]), true)
+	if err != nil {
+		return serverFunctionError(errorInfo, err)
+	}
+
+	serverFunctions.RLock()
+	handler, ok := serverFunctions.handlers[goFuncName]
+	serverFunctions.RUnlock()
+	if !ok {
+		return serverFunctionError(errorInfo, goRfcError(fmt.Sprintf("No server function installed for \"%v\"", goFuncName), nil))
+	}
+
+	params, err := wrapResult(funcDesc, funcHandle, C.RFC_EXPORT, wrapOptions{strip: true})
+	if err != nil {
+		return serverFunctionError(errorInfo, err)
+	}
+
+	result, err := handler(params)
+	if err != nil {
+		return serverFunctionError(errorInfo, err)
+	}
+
+	for name, value := range result {
+		err = fillFunctionParameter(funcDesc, funcHandle, name, value, nil)
+		if err != nil {
+			return serverFunctionError(errorInfo, err)
+		}
+	}
+	return C.RFC_OK
+}
+
+// serverFunctionError reports the Go error to ABAP as ABAP exception, ABAP message or SYSTEM_FAILURE
+func serverFunctionError(errorInfo *C.RFC_ERROR_INFO, err error) C.RFC_RC {
+	var abapError AbapApplicationError
+	if !errors.As(err, &abapError) {
+		errorInfo.code = C.RFC_EXTERNAL_FAILURE
+		errorInfo.group = C.EXTERNAL_APPLICATION_FAILURE
+		fillBuffer(errorInfo.message[:], err.Error())
+		return errorInfo.code
+	}
+
+	abapErrorInfo := abapError.ErrorInfo
+	errorInfo.group = C.ABAP_APPLICATION_FAILURE
+	if abapErrorInfo.AbapMsgClass != "" {
+		errorInfo.code = C.RFC_ABAP_MESSAGE
+		fillBuffer(errorInfo.abapMsgClass[:], abapErrorInfo.AbapMsgClass)
+		fillBuffer(errorInfo.abapMsgType[:], abapErrorInfo.AbapMsgType)
+		fillBuffer(errorInfo.abapMsgNumber[:], abapErrorInfo.AbapMsgNumber)
+		fillBuffer(errorInfo.abapMsgV1[:], abapErrorInfo.AbapMsgV1)
+		fillBuffer(errorInfo.abapMsgV2[:], abapErrorInfo.AbapMsgV2)
+		fillBuffer(errorInfo.abapMsgV3[:], abapErrorInfo.AbapMsgV3)
+		fillBuffer(errorInfo.abapMsgV4[:], abapErrorInfo.AbapMsgV4)
+	} else {
+		errorInfo.code = C.RFC_ABAP_EXCEPTION
+	}
+	fillBuffer(errorInfo.key[:], abapErrorInfo.Key)
+	fillBuffer(errorInfo.message[:], abapErrorInfo.Message)
+	return errorInfo.code
+}
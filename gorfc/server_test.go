@@ -0,0 +1,103 @@
+package gorfc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Server Tests
+//
+
+func abapServer() ConnectionParameters {
+	return ConnectionParameters{
+		"gwhost":     "10.68.110.51",
+		"gwserv":     "sapgw00",
+		"program_id": "GORFC_TEST_SERVER",
+	}
+}
+
+// abapServerClient opens the client connection to the registered server
+func abapServerClient() ConnectionParameters {
+	return ConnectionParameters{
+		"gwhost": "10.68.110.51",
+		"gwserv": "sapgw00",
+		"tpname": "GORFC_TEST_SERVER",
+	}
+}
+
+func TestServerStartShutdown(t *testing.T) {
+	fmt.Println("Server test: Start and Shutdown")
+	s, err := ServerFromParams(abapServer(), abapSystem())
+	assert.Nil(t, err)
+
+	err = s.AddFunction("STFC_CONNECTION", func(params map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"ECHOTEXT": params["REQUTEXT"],
+			"RESPTEXT": "Go server",
+		}, nil
+	})
+	assert.Nil(t, err)
+
+	err = s.AddFunction("NON_EXISTING_FUNCTION", nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Could not get function description for \"NON_EXISTING_FUNCTION\"", err.(*RfcError).Description)
+
+	assert.Nil(t, s.Start())
+	assert.NotNil(t, s.Start())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, s.Shutdown(ctx))
+	assert.Nil(t, s.Shutdown(ctx))
+}
+
+func TestServerCall(t *testing.T) {
+	fmt.Println("Server test: Call server function")
+	s, err := ServerFromParams(abapServer(), abapSystem())
+	assert.Nil(t, err)
+	err = s.AddFunction("STFC_CONNECTION", func(params map[string]interface{}) (map[string]interface{}, error) {
+		switch params["REQUTEXT"] {
+		case "exception":
+			return nil, &RfcError{"Server function failed", RfcErrorInfo{Group: AbapApplicationFailure, Key: "GORFC_TEST", Message: "Test exception"}}
+		case "panic":
+			panic("test panic")
+		}
+		return map[string]interface{}{
+			"ECHOTEXT": params["REQUTEXT"],
+			"RESPTEXT": "Go server",
+		}, nil
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, s.Start())
+
+	c, err := ConnectionFromParams(abapServerClient())
+	assert.Nil(t, err)
+	r, err := c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "Hällö"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Hällö", r["ECHOTEXT"])
+	assert.Equal(t, "Go server", r["RESPTEXT"])
+
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "exception"})
+	assert.True(t, errors.Is(err, AbapApplicationFailure))
+	assert.Equal(t, "GORFC_TEST", err.(*RfcError).ErrorInfo.Key)
+
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "panic"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.(*RfcError).ErrorInfo.Message, "Server function panic: test panic")
+
+	// the server connection is kept after errors
+	r, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "again"})
+	assert.Nil(t, err)
+	assert.Equal(t, "again", r["ECHOTEXT"])
+	c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, s.Shutdown(ctx))
+}
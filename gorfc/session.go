@@ -0,0 +1,228 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+//################################################################################
+//# SESSION                                                                      #
+//################################################################################
+
+// Session pins a connection for a sequence of calls running in the same ABAP user context,
+// like BAPI calls followed by BAPI_TRANSACTION_COMMIT. While the session is active, calls
+// over the connection are only possible by the session, other Connection methods using the
+// connection handle, like Ping, Close or NewTransaction, return an error. The Session is safe for concurrent use,
+// calls are serialized.
+//
+// Session calls are never retried and the connection is never reopened, because the ABAP user context
+// would be lost with it. When the connection breaks, the session ends and the error is returned.
+type Session struct {
+	mu    sync.Mutex
+	conn  *Connection
+	pool  *Pool
+	ended bool
+}
+
+var _ Client = (*Session)(nil)
+
+// BeginSession starts the session pinning the connection, until End.
+func (conn *Connection) BeginSession() (session *Session, err error) {
+	if !conn.alive {
+		return nil, goRfcError("BeginSession() method requires an open connection", nil)
+	}
+	conn.sessionMu.Lock()
+	defer conn.sessionMu.Unlock()
+	if conn.session != nil {
+		return nil, goRfcError("Connection is already pinned by a session", nil)
+	}
+	session = &Session{conn: conn}
+	conn.session = session
+	return
+}
+
+// pinned returns true if the connection is pinned by a session
+func (conn *Connection) pinned() bool {
+	conn.sessionMu.Lock()
+	defer conn.sessionMu.Unlock()
+	return conn.session != nil
+}
+
+// checkPinned returns the error if the connection is pinned by a session, for methods using the connection handle
+func (conn *Connection) checkPinned(method string) error {
+	if conn.pinned() {
+		return goRfcError(fmt.Sprintf("Connection is pinned by a session, %v() method not allowed", method), nil)
+	}
+	return nil
+}
+
+// BeginSession starts the session with a connection from the pool, put back by End.
+func (pool *Pool) BeginSession(ctx context.Context) (session *Session, err error) {
+	conn, err := pool.Get(ctx)
+	if err != nil {
+		return
+	}
+	session, err = conn.BeginSession()
+	if err != nil {
+		pool.Put(conn)
+		return
+	}
+	session.pool = pool
+	return
+}
+
+// ResetServerContext resets the ABAP user context of the connection by RfcResetServerContext,
+// discarding the uncommitted changes, without closing the connection.
+func (conn *Connection) ResetServerContext() (err error) {
+	if err = conn.checkPinned("ResetServerContext"); err != nil {
+		return
+	}
+	return conn.resetServerContext()
+}
+
+func (conn *Connection) resetServerContext() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	if !conn.alive {
+		return goRfcError("ResetServerContext() method requires an open connection", nil)
+	}
+	rc := C.RfcResetServerContext(conn.handle, &errorInfo)
+	if rc != C.RFC_OK {
+		err = rfcError(errorInfo, "Server context could not be reset")
+		conn.checkBroken(err)
+	}
+	return
+}
+
+// Call calls the given function with the given parameters in the session.
+func (session *Session) Call(goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	return session.CallContext(context.Background(), goFuncName, params)
+}
+
+// CallContext calls the given function with the given parameters in the session, see Connection.CallContext.
+func (session *Session) CallContext(ctx context.Context, goFuncName string, params interface{}) (result map[string]interface{}, err error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ended {
+		return nil, goRfcError("Session has ended", nil)
+	}
+	result, err = session.conn.call(ctx, goFuncName, params)
+	return result, session.checkBroken(err)
+}
+
+// checkBroken ends the session if err broke the connection, the ABAP user context is lost
+func (session *Session) checkBroken(err error) error {
+	if err == nil || session.conn.alive {
+		return err
+	}
+	// the broken connection can't be reset, the end error is expected
+	session.end()
+	return goRfcError("Session ended, the connection is broken", err)
+}
+
+// Commit calls BAPI_TRANSACTION_COMMIT, waiting for the update to complete.
+// The error or abort message returned by BAPI_TRANSACTION_COMMIT is returned as error.
+func (session *Session) Commit(ctx context.Context) (err error) {
+	result, err := session.CallContext(ctx, "BAPI_TRANSACTION_COMMIT", map[string]interface{}{"WAIT": "X"})
+	if err != nil {
+		return
+	}
+	if ret, ok := result["RETURN"].(map[string]interface{}); ok && (ret["TYPE"] == "E" || ret["TYPE"] == "A") {
+		return goRfcError(fmt.Sprintf("Commit failed: %v", ret["MESSAGE"]), nil)
+	}
+	return
+}
+
+// Rollback calls BAPI_TRANSACTION_ROLLBACK.
+func (session *Session) Rollback(ctx context.Context) (err error) {
+	_, err = session.CallContext(ctx, "BAPI_TRANSACTION_ROLLBACK", nil)
+	return
+}
+
+// ResetServerContext resets the ABAP user context of the session connection, see Connection.ResetServerContext.
+func (session *Session) ResetServerContext() error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ended {
+		return goRfcError("Session has ended", nil)
+	}
+	return session.checkBroken(session.conn.resetServerContext())
+}
+
+// Ping pings the server of the session connection, without reopening it.
+func (session *Session) Ping() error {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ended {
+		return goRfcError("Session has ended", nil)
+	}
+	if !session.conn.alive {
+		return session.checkBroken(goRfcError("Ping() method requires an open connection", nil))
+	}
+	return session.checkBroken(session.conn.ping())
+}
+
+// GetFunctionDescription returns the wrapped function description of the given function.
+func (session *Session) GetFunctionDescription(goFuncName string) (goFuncDesc FunctionDescription, err error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ended {
+		return goFuncDesc, goRfcError("Session has ended", nil)
+	}
+	return session.conn.getFunctionDescription(goFuncName)
+}
+
+// GetConnectionAttributes returns the wrapped connection attributes of the session connection.
+func (session *Session) GetConnectionAttributes() (connAttr ConnectionAttributes, err error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ended {
+		return nil, goRfcError("Session has ended", nil)
+	}
+	return session.conn.getConnectionAttributes()
+}
+
+// End resets the server context and unpins the connection. The session from pool puts the connection back,
+// closed if the server context could not be reset. Calling End again does nothing.
+func (session *Session) End() (err error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.end()
+}
+
+func (session *Session) end() (err error) {
+	if session.ended {
+		return
+	}
+	session.ended = true
+	conn := session.conn
+	conn.sessionMu.Lock()
+	conn.session = nil
+	conn.sessionMu.Unlock()
+	if conn.alive {
+		err = conn.resetServerContext()
+	}
+	if session.pool != nil {
+		if err != nil {
+			conn.Close()
+		}
+		if putErr := session.pool.Put(conn); err == nil {
+			err = putErr
+		}
+	}
+	return
+}
+
+// Close ends the session, see End. The session connection is not closed.
+func (session *Session) Close() error {
+	return session.End()
+}
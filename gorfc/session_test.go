@@ -0,0 +1,118 @@
+package gorfc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Session Tests
+//
+
+func TestSessionPinsConnection(t *testing.T) {
+	fmt.Println("Session: Connection pinned")
+	c := &Connection{alive: true}
+	session, err := c.BeginSession()
+	assert.Nil(t, err)
+	_, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "pinned"})
+	assert.Equal(t, "Connection is pinned by a session, use Session.Call()", err.(*GoRfcError).Description)
+	_, err = c.BeginSession()
+	assert.Equal(t, "Connection is already pinned by a session", err.(*GoRfcError).Description)
+	assert.Equal(t, "Connection is pinned by a session, Ping() method not allowed", c.Ping().(*GoRfcError).Description)
+	assert.Equal(t, "Connection is pinned by a session, Close() method not allowed", c.Close().(*GoRfcError).Description)
+	assert.Equal(t, "Connection is pinned by a session, Reopen() method not allowed", c.Reopen().(*GoRfcError).Description)
+	_, err = c.GetFunctionDescription("STFC_CONNECTION")
+	assert.Equal(t, "Connection is pinned by a session, GetFunctionDescription() method not allowed", err.(*GoRfcError).Description)
+	_, err = c.NewTransaction("")
+	assert.Equal(t, "Connection is pinned by a session, NewTransaction() method not allowed", err.(*GoRfcError).Description)
+	_, err = c.CreateUnit()
+	assert.Equal(t, "Connection is pinned by a session, CreateUnit() method not allowed", err.(*GoRfcError).Description)
+	assert.True(t, c.alive)
+
+	c.alive = false
+	assert.Nil(t, session.End())
+	assert.Nil(t, session.End())
+	_, err = session.Call("STFC_CONNECTION", nil)
+	assert.Equal(t, "Session has ended", err.(*GoRfcError).Description)
+	_, err = c.BeginSession()
+	assert.Equal(t, "BeginSession() method requires an open connection", err.(*GoRfcError).Description)
+}
+
+func TestSessionBrokenConnection(t *testing.T) {
+	fmt.Println("Session: Broken connection ends the session")
+	c := &Connection{alive: true, retryPolicy: &RetryPolicy{MaxAttempts: 3, Idempotent: []string{"STFC_CONNECTION"}}}
+	session, err := c.BeginSession()
+	assert.Nil(t, err)
+	c.alive = false
+	_, err = session.Call("STFC_CONNECTION", nil)
+	assert.Equal(t, "Session ended, the connection is broken", err.(*GoRfcError).Description)
+	assert.Equal(t, "Call() method requires an open connection", err.(*GoRfcError).GoError.(*GoRfcError).Description)
+	assert.False(t, c.alive)
+	assert.False(t, c.pinned())
+	assert.Equal(t, "Session has ended", session.Ping().(*GoRfcError).Description)
+
+	c.alive = true
+	session, err = c.BeginSession()
+	assert.Nil(t, err)
+	c.alive = false
+	assert.Equal(t, "Session ended, the connection is broken", session.Ping().(*GoRfcError).Description)
+	assert.False(t, c.alive)
+	assert.False(t, c.pinned())
+}
+
+func TestSessionConcurrentBegin(t *testing.T) {
+	fmt.Println("Session: One session pins the connection")
+	c := &Connection{alive: true}
+	var wg sync.WaitGroup
+	var begun int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.BeginSession(); err == nil {
+				atomic.AddInt32(&begun, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), begun)
+}
+
+func TestSessionCommit(t *testing.T) {
+	fmt.Println("Session: BAPI call and commit")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	session, err := c.BeginSession()
+	assert.Nil(t, err)
+	r, err := session.Call("BAPI_USER_GET_DETAIL", map[string]interface{}{"USERNAME": "DEMO"})
+	assert.Nil(t, err)
+	assert.NotNil(t, r["ADDRESS"])
+	assert.Nil(t, session.Commit(context.Background()))
+	assert.Nil(t, session.ResetServerContext())
+	assert.Nil(t, session.End())
+
+	r, err = c.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "unpinned"})
+	assert.Nil(t, err)
+	assert.Equal(t, "unpinned", r["ECHOTEXT"])
+	c.Close()
+}
+
+func TestSessionPool(t *testing.T) {
+	fmt.Println("Session: Pool connection returned clean")
+	pool, err := PoolFromParams(abapSystem(), PoolOptions{MaxConnections: 1})
+	assert.Nil(t, err)
+	session, err := pool.BeginSession(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, pool.Stats().InUse)
+	_, err = session.Call("STFC_CONNECTION", map[string]interface{}{"REQUTEXT": "session"})
+	assert.Nil(t, err)
+	assert.Nil(t, session.Rollback(context.Background()))
+	assert.Nil(t, session.End())
+	assert.Equal(t, PoolStats{MaxConnections: 1, OpenConnections: 1, Idle: 1}, pool.Stats())
+	pool.Close()
+}
@@ -0,0 +1,89 @@
+package gorfc
+
+import (
+	"reflect"
+	"strings"
+)
+
+//################################################################################
+//# STRUCT TAGS                                                                  #
+//################################################################################
+//# Go structure fields are mapped to ABAP names by the `rfc:"NAME"` tag.
+//# Fields without the tag are mapped by the upper-cased Go field name,
+//# `rfc:"-"` skips the field and embedded structures are flattened.
+//# When filling parameters, `rfc:"NAME,omitempty"` skips zero values and
+//# nil pointers leave the ABAP initial value.
+
+// structField is a Go structure field mapped to an ABAP parameter or field name
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+func parseTag(field reflect.StructField) (name string, omitEmpty bool) {
+	tag := field.Tag.Get("rfc")
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return
+}
+
+// structFields returns the fields of the Go structure type, with embedded structures flattened
+func structFields(t reflect.Type) (fields []structField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty := parseTag(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				if field.PkgPath != "" {
+					// unexported embedded pointer can not be followed
+					continue
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, embedded := range structFields(ft) {
+					embedded.index = append([]int{i}, embedded.index...)
+					fields = append(fields, embedded)
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = strings.ToUpper(field.Name)
+		}
+		fields = append(fields, structField{name, []int{i}, omitEmpty})
+	}
+	return
+}
+
+// fieldByIndex returns the nested structure field, allocating nil embedded pointers if alloc is set.
+// Returns false if the field is not reachable over a nil pointer.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
@@ -0,0 +1,342 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+)
+
+//################################################################################
+//# TRANSACTIONAL RFC                                                            #
+//################################################################################
+
+// TIDState is the state of a transaction or unit ID
+type TIDState string
+
+const (
+	// TIDCreated transaction ID is assigned, the transaction is not yet executed
+	TIDCreated TIDState = "created"
+	// TIDExecuted transaction is submitted and executed, not yet confirmed
+	TIDExecuted TIDState = "executed"
+)
+
+// TIDStore persists transaction IDs until confirmed, so that the transaction
+// can be resumed with the same TID after a crash, without posting it twice.
+type TIDStore interface {
+	// Save records the state of the transaction ID
+	Save(tid string, queueName string, state TIDState) error
+	// Delete removes the confirmed transaction ID
+	Delete(tid string) error
+	// Pending returns transaction IDs not yet confirmed
+	Pending() ([]PendingTID, error)
+}
+
+// PendingTID is a transaction ID not yet confirmed
+type PendingTID struct {
+	TID       string
+	QueueName string
+	State     TIDState
+}
+
+// DefaultTIDStoreFile is used when no TIDStore is set on the connection
+var DefaultTIDStoreFile = "gorfc_tid.json"
+
+var defaultTIDStore = struct {
+	sync.Mutex
+	store TIDStore
+}{}
+
+// FileTIDStore is a TIDStore persisting transaction IDs in a JSON file
+type FileTIDStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileTIDStore returns the TIDStore persisting transaction IDs in the file at path
+func NewFileTIDStore(path string) *FileTIDStore {
+	return &FileTIDStore{path: path}
+}
+
+func (store *FileTIDStore) load() (tids map[string]PendingTID, err error) {
+	tids = make(map[string]PendingTID)
+	err = readJSONFile(store.path, &tids)
+	return
+}
+
+// readJSONFile unmarshals the JSON file, a missing file leaves v unchanged
+func readJSONFile(path string, v interface{}) (err error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSONFile marshals v into a temporary file renamed to path, not to leave a partial file on crash
+func writeJSONFile(path string, v interface{}) (err error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Save records the state of the transaction ID
+func (store *FileTIDStore) Save(tid string, queueName string, state TIDState) (err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	tids, err := store.load()
+	if err != nil {
+		return
+	}
+	tids[tid] = PendingTID{tid, queueName, state}
+	return writeJSONFile(store.path, tids)
+}
+
+// Delete removes the confirmed transaction ID
+func (store *FileTIDStore) Delete(tid string) (err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	tids, err := store.load()
+	if err != nil {
+		return
+	}
+	if _, ok := tids[tid]; !ok {
+		return
+	}
+	delete(tids, tid)
+	return writeJSONFile(store.path, tids)
+}
+
+// Pending returns transaction IDs not yet confirmed
+func (store *FileTIDStore) Pending() (pending []PendingTID, err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	tids, err := store.load()
+	if err != nil {
+		return
+	}
+	for _, tid := range tids {
+		pending = append(pending, tid)
+	}
+	return
+}
+
+// Transaction groups function calls executed exactly once in the ABAP system
+type Transaction struct {
+	conn      *Connection
+	handle    C.RFC_TRANSACTION_HANDLE
+	tid       string
+	queueName string
+	submitted bool
+}
+
+func (conn *Connection) getTIDStore() TIDStore {
+	if conn.tidStore == nil {
+		defaultTIDStore.Lock()
+		if defaultTIDStore.store == nil {
+			defaultTIDStore.store = NewFileTIDStore(DefaultTIDStoreFile)
+		}
+		defaultTIDStore.Unlock()
+		conn.tidStore = defaultTIDStore.store
+	}
+	return conn.tidStore
+}
+
+// NewTransaction creates a new transaction with the TID assigned by the ABAP system.
+// The queueName is set for queued RFC (qRFC), empty for transactional RFC (tRFC).
+func (conn *Connection) NewTransaction(queueName string) (transaction *Transaction, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	var tid C.RFC_TID
+
+	if err = conn.checkPinned("NewTransaction"); err != nil {
+		return
+	}
+	if !conn.alive {
+		return nil, goRfcError("NewTransaction() method requires an open connection", nil)
+	}
+
+	rc := C.RfcGetTransactionID(conn.handle, &tid[0], &errorInfo)
+	if rc != C.RFC_OK {
+		return nil, rfcError(errorInfo, "Could not get transaction ID")
+	}
+	goTID, err := nWrapString((*C.SAP_UC)(&tid[0]), 24, true)
+	if err != nil {
+		return
+	}
+
+	transaction, err = conn.createTransaction(goTID, queueName)
+	if err != nil {
+		return
+	}
+
+	err = conn.getTIDStore().Save(goTID, queueName, TIDCreated)
+	if err != nil {
+		transaction.Destroy()
+		return nil, goRfcError(fmt.Sprintf("Could not save transaction ID %v", goTID), err)
+	}
+	return
+}
+
+// ResumeTransaction creates the transaction with the TID of a pending transaction,
+// not confirmed before a crash. The ABAP system executes the transaction only once.
+func (conn *Connection) ResumeTransaction(tid string, queueName string) (transaction *Transaction, err error) {
+	if err = conn.checkPinned("ResumeTransaction"); err != nil {
+		return
+	}
+	if !conn.alive {
+		return nil, goRfcError("ResumeTransaction() method requires an open connection", nil)
+	}
+	return conn.createTransaction(tid, queueName)
+}
+
+func (conn *Connection) createTransaction(goTID string, goQueueName string) (transaction *Transaction, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	var tid C.RFC_TID
+	var queueName *C.SAP_UC
+
+	err = fillBuffer(tid[:], goTID)
+	if err != nil {
+		return
+	}
+	if goQueueName != "" {
+		queueName, err = fillString(goQueueName)
+		defer C.free(unsafe.Pointer(queueName))
+		if err != nil {
+			return
+		}
+	}
+
+	handle := C.RfcCreateTransaction(conn.handle, &tid[0], queueName, &errorInfo)
+	if handle == nil {
+		return nil, rfcError(errorInfo, "Could not create transaction %v", goTID)
+	}
+	return &Transaction{conn: conn, handle: handle, tid: goTID, queueName: goQueueName}, nil
+}
+
+// TID returns the transaction ID
+func (transaction *Transaction) TID() string {
+	return transaction.tid
+}
+
+// AddFunction adds the function call with given parameters to the transaction.
+func (transaction *Transaction) AddFunction(goFuncName string, params interface{}) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if transaction.submitted {
+		return goRfcError("AddFunction() method requires a not submitted transaction", nil)
+	}
+	if err = transaction.conn.checkPinned("AddFunction"); err != nil {
+		return
+	}
+
+	_, funcCont, err := transaction.conn.createFunction(goFuncName, params)
+	if err != nil {
+		return
+	}
+	defer transaction.conn.releaseFunctionDesc()
+	defer C.RfcDestroyFunction(funcCont, nil)
+
+	rc := C.RfcInvokeInTransaction(transaction.handle, funcCont, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not add function \"%v\" to transaction %v", goFuncName, transaction.tid)
+	}
+	return
+}
+
+// Submit sends the transaction to the ABAP system, to be executed once.
+// On error, Submit can be repeated while the connection is open. The transaction belongs to the connection
+// which created it: when the connection broke, destroy the transaction, resume it by ResumeTransaction with the
+// same TID on an open connection, add the functions again and submit.
+func (transaction *Transaction) Submit() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if err = transaction.conn.checkPinned("Submit"); err != nil {
+		return
+	}
+
+	rc := C.RfcSubmitTransaction(transaction.handle, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not submit transaction %v", transaction.tid)
+	}
+	transaction.submitted = true
+
+	err = transaction.conn.getTIDStore().Save(transaction.tid, transaction.queueName, TIDExecuted)
+	if err != nil {
+		return goRfcError(fmt.Sprintf("Could not save transaction ID %v", transaction.tid), err)
+	}
+	return
+}
+
+// Confirm lets the ABAP system delete the executed transaction ID, removes it from the TIDStore
+// and releases the transaction.
+func (transaction *Transaction) Confirm() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if !transaction.submitted {
+		return goRfcError("Confirm() method requires a submitted transaction", nil)
+	}
+	if err = transaction.conn.checkPinned("Confirm"); err != nil {
+		return
+	}
+
+	rc := C.RfcConfirmTransaction(transaction.handle, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not confirm transaction %v", transaction.tid)
+	}
+	defer func() {
+		if destroyErr := transaction.Destroy(); err == nil {
+			err = destroyErr
+		}
+	}()
+
+	err = transaction.conn.getTIDStore().Delete(transaction.tid)
+	if err != nil {
+		return goRfcError(fmt.Sprintf("Could not delete transaction ID %v", transaction.tid), err)
+	}
+	return
+}
+
+// Destroy releases the transaction, without confirming it. The TID remains pending in the TIDStore.
+func (transaction *Transaction) Destroy() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	if transaction.handle == nil {
+		return
+	}
+	rc := C.RfcDestroyTransaction(transaction.handle, &errorInfo)
+	transaction.handle = nil
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not destroy transaction %v", transaction.tid)
+	}
+	return
+}
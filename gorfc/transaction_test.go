@@ -0,0 +1,65 @@
+package gorfc
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Transactional RFC Tests
+//
+
+func TestFileTIDStore(t *testing.T) {
+	fmt.Println("tRFC: File TID store")
+	path := filepath.Join(t.TempDir(), "tid.json")
+	store := NewFileTIDStore(path)
+
+	pending, err := store.Pending()
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+
+	assert.Nil(t, store.Save("TID1", "", TIDCreated))
+	assert.Nil(t, store.Save("TID2", "QUEUE", TIDCreated))
+	assert.Nil(t, store.Save("TID1", "", TIDExecuted))
+	assert.Nil(t, store.Delete("TID2"))
+	assert.Nil(t, store.Delete("TID3"))
+
+	// reloaded from file
+	pending, err = NewFileTIDStore(path).Pending()
+	assert.Nil(t, err)
+	assert.Equal(t, []PendingTID{{"TID1", "", TIDExecuted}}, pending)
+}
+
+func TestTransaction(t *testing.T) {
+	fmt.Println("tRFC: Submit and confirm")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	store := NewFileTIDStore(filepath.Join(t.TempDir(), "tid.json"))
+	c.TIDStore(store)
+
+	tr, err := c.NewTransaction("")
+	assert.Nil(t, err)
+	assert.Equal(t, 24, len(tr.TID()))
+
+	params := map[string]interface{}{
+		"TCPICDAT": []map[string]interface{}{
+			{"LINE": "Hello tRFC"},
+		},
+	}
+	assert.Nil(t, tr.AddFunction("STFC_WRITE_TO_TCPIC", params))
+	assert.Nil(t, tr.AddFunction("STFC_WRITE_TO_TCPIC", params))
+	assert.NotNil(t, tr.Confirm())
+
+	assert.Nil(t, tr.Submit())
+	pending, _ := store.Pending()
+	assert.Equal(t, []PendingTID{{tr.TID(), "", TIDExecuted}}, pending)
+	assert.NotNil(t, tr.AddFunction("STFC_WRITE_TO_TCPIC", params))
+
+	assert.Nil(t, tr.Confirm())
+	pending, _ = store.Pending()
+	assert.Empty(t, pending)
+	c.Close()
+}
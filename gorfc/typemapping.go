@@ -0,0 +1,178 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//################################################################################
+//# TYPE MAPPING                                                                 #
+//################################################################################
+
+// Converter converts the fields of ABAP structure into the Go value returned instead of the fields map
+type Converter func(fields map[string]interface{}) (interface{}, error)
+
+// TypeMapping selects the Go types of ABAP values returned by the connection.
+// The zero value keeps the default mapping. Input values of the alternative Go types are always accepted.
+type TypeMapping struct {
+	// NumcAsInt64 returns NUMC of up to 18 digits as int64 instead of string, longer NUMC always as string
+	NumcAsInt64 bool
+	// DateAsDate returns DATE as Date instead of time.Time
+	DateAsDate bool
+	// TimeAsDuration returns TIME as time.Duration since midnight instead of time.Time on year 0
+	TimeAsDuration bool
+	// Char1AsBool names the CHAR parameters and fields of length 1 returned as bool, true for "X" and false for blank.
+	// Other values of the named flags fail the call, CHAR1 values not named, like BAPIRET2 message type, are returned as string.
+	Char1AsBool []string
+
+	converters map[string]Converter
+}
+
+// RegisterConverter registers the converter of structures and table lines of the given DDIC type,
+// like BAPIRET2, and returns the type mapping
+func (mapping *TypeMapping) RegisterConverter(typeName string, converter Converter) *TypeMapping {
+	if mapping.converters == nil {
+		mapping.converters = make(map[string]Converter)
+	}
+	mapping.converters[typeName] = converter
+	return mapping
+}
+
+// char1AsBool returns true if the CHAR1 parameter or field is returned as bool
+func (mapping *TypeMapping) char1AsBool(name string) bool {
+	if mapping == nil {
+		return false
+	}
+	for _, flag := range mapping.Char1AsBool {
+		if flag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// converter returns the converter registered for the DDIC type, nil if none
+func (mapping *TypeMapping) converter(typeName string) Converter {
+	if mapping == nil {
+		return nil
+	}
+	return mapping.converters[typeName]
+}
+
+// TypeMapping sets the type mapping of the given connection and returns the connection, nil for default mapping
+func (conn *Connection) TypeMapping(typeMapping *TypeMapping) *Connection {
+	conn.typeMapping = typeMapping
+	return conn
+}
+
+// Date is the ABAP DATE value, without time and location
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of the time, in the time location
+func DateOf(t time.Time) Date {
+	var d Date
+	d.Year, d.Month, d.Day = t.Date()
+	return d
+}
+
+// ParseDate parses the date in "2006-01-02" or ABAP "20060102" format
+func ParseDate(s string) (d Date, err error) {
+	layout := "2006-01-02"
+	if len(s) == 8 {
+		layout = "20060102"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return d, goRfcError(fmt.Sprintf("Invalid date %q", s), err)
+	}
+	return DateOf(t), nil
+}
+
+// In returns the time at midnight of the date in the given location
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero returns true for the zero value
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String returns the date in "2006-01-02" format
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Date) UnmarshalText(text []byte) (err error) {
+	*d, err = ParseDate(string(text))
+	return
+}
+
+// abapDate returns the ABAP DATE value "YYYYMMDD"
+func (d Date) abapDate() string {
+	return fmt.Sprintf("%04d%02d%02d", d.Year, d.Month, d.Day)
+}
+
+// abapTime returns the ABAP TIME value "HHMMSS" of the duration since midnight
+func abapTime(duration time.Duration) (string, error) {
+	if duration < 0 || duration >= 24*time.Hour {
+		return "", goRfcError(fmt.Sprintf("TIME value %v out of range 0s to 23h59m59s", duration), nil)
+	}
+	seconds := int(duration / time.Second)
+	return fmt.Sprintf("%02d%02d%02d", seconds/3600, seconds/60%60, seconds%60), nil
+}
+
+// maxNumcAsInt64 is the length of the longest NUMC always fitting into int64
+const maxNumcAsInt64 = 18
+
+// mapNumc returns the value of NUMC up to 18 digits as int64, the initial value as 0.
+// Values of longer NUMC are returned unchanged, the Go type depends on the length only.
+func mapNumc(value string, nucLength uint) (interface{}, error) {
+	if nucLength > maxNumcAsInt64 {
+		return value, nil
+	}
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return int64(0), nil
+	}
+	i, err := strconv.ParseInt(trimmed, 10, 64)
+	if err != nil {
+		return nil, goRfcError(fmt.Sprintf("NUMC value %q can not be returned as int64", value), nil)
+	}
+	return i, nil
+}
+
+// mapTime returns the ABAP TIME value "HHMMSS" as duration since midnight
+func mapTime(value string) (interface{}, error) {
+	t, err := time.Parse("150405", value)
+	if err != nil {
+		return nil, goRfcError("Error parsing ABAP RFC_TIME field", err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// mapChar1 returns the ABAP flag as bool, the error for other values
+func mapChar1(name string, value string) (bool, error) {
+	switch value {
+	case "X":
+		return true, nil
+	case "", " ":
+		return false, nil
+	}
+	return false, goRfcError(fmt.Sprintf("CHAR1 value %q of %v can not be returned as bool", value, name), nil)
+}
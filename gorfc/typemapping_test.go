@@ -0,0 +1,135 @@
+package gorfc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Type Mapping Tests
+//
+
+func TestDate(t *testing.T) {
+	fmt.Println("Type mapping: Date")
+	d, err := ParseDate("20240229")
+	assert.Nil(t, err)
+	assert.Equal(t, Date{2024, time.February, 29}, d)
+	assert.Equal(t, "2024-02-29", d.String())
+	assert.Equal(t, "20240229", d.abapDate())
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), d.In(time.UTC))
+	assert.Equal(t, d, DateOf(time.Date(2024, 2, 29, 23, 59, 59, 0, time.Local)))
+	assert.False(t, d.IsZero())
+	assert.True(t, Date{}.IsZero())
+
+	d, err = ParseDate("0001-01-01")
+	assert.Nil(t, err)
+	assert.Equal(t, "00010101", d.abapDate())
+	_, err = ParseDate("20240230")
+	assert.NotNil(t, err)
+
+	var item struct {
+		Deliv Date `rfc:"DELIV_DATE"`
+	}
+	assert.Nil(t, Decode(map[string]interface{}{"DELIV_DATE": time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}, &item))
+	assert.Equal(t, Date{2024, time.February, 29}, item.Deliv)
+}
+
+func TestTypeMappingValues(t *testing.T) {
+	fmt.Println("Type mapping: NUMC, TIME and CHAR1")
+	n, err := mapNumc("0000012345", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12345), n)
+	n, _ = mapNumc("", 10)
+	assert.Equal(t, int64(0), n)
+	n, _ = mapNumc("999999999999999999", 18)
+	assert.Equal(t, int64(999999999999999999), n)
+	// the Go type depends on the NUMC length, not on the value
+	n, _ = mapNumc("00000000000000000001", 20)
+	assert.Equal(t, "00000000000000000001", n)
+	n, _ = mapNumc("12345678901234567890", 20)
+	assert.Equal(t, "12345678901234567890", n)
+	_, err = mapNumc("12A", 3)
+	assert.Equal(t, "NUMC value \"12A\" can not be returned as int64", err.(*GoRfcError).Description)
+
+	value, err := mapTime("235959")
+	assert.Nil(t, err)
+	assert.Equal(t, 23*time.Hour+59*time.Minute+59*time.Second, value)
+	value, err = mapTime("000000")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), value)
+	s, err := abapTime(13*time.Hour + 5*time.Minute + 7*time.Second + 500*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "130507", s)
+	_, err = abapTime(24 * time.Hour)
+	assert.Equal(t, "TIME value 24h0m0s out of range 0s to 23h59m59s", err.(*GoRfcError).Description)
+	_, err = abapTime(-time.Second)
+	assert.NotNil(t, err)
+
+	flag, err := mapChar1("RFCCHAR1", "X")
+	assert.Nil(t, err)
+	assert.True(t, flag)
+	for _, blank := range []string{"", " "} {
+		flag, err = mapChar1("RFCCHAR1", blank)
+		assert.Nil(t, err)
+		assert.False(t, flag)
+	}
+	_, err = mapChar1("TYPE", "E")
+	assert.Equal(t, "CHAR1 value \"E\" of TYPE can not be returned as bool", err.(*GoRfcError).Description)
+
+	mapping := &TypeMapping{Char1AsBool: []string{"RFCCHAR1", "NO_USERS"}}
+	assert.True(t, mapping.char1AsBool("NO_USERS"))
+	assert.False(t, mapping.char1AsBool("TYPE"))
+	assert.False(t, (*TypeMapping)(nil).char1AsBool("RFCCHAR1"))
+}
+
+func TestTypeMappingConverters(t *testing.T) {
+	fmt.Println("Type mapping: Converters")
+	mapping := &TypeMapping{}
+	assert.Nil(t, mapping.converter("BAPIRET2"))
+	assert.Nil(t, (*TypeMapping)(nil).converter("BAPIRET2"))
+	mapping.RegisterConverter("BAPIRET2", func(fields map[string]interface{}) (interface{}, error) {
+		return fmt.Sprintf("%v: %v", fields["TYPE"], fields["MESSAGE"]), nil
+	})
+	value, err := mapping.converter("BAPIRET2")(map[string]interface{}{"TYPE": "E", "MESSAGE": "User not found"})
+	assert.Nil(t, err)
+	assert.Equal(t, "E: User not found", value)
+	assert.Nil(t, mapping.converter("RFCTEST"))
+
+	value, err = unmarshalValue(marshalValue(map[string]interface{}{"DATE": Date{2024, time.February, 29}, "TIME": 90 * time.Minute}))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"DATE": Date{2024, time.February, 29}, "TIME": 90 * time.Minute}, value)
+}
+
+type bapiMessage struct {
+	Type    string
+	Message string
+}
+
+func TestTypeMappingCall(t *testing.T) {
+	fmt.Println("Type mapping: Call with alternative types")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	mapping := &TypeMapping{NumcAsInt64: true, DateAsDate: true, TimeAsDuration: true, Char1AsBool: []string{"RFCCHAR1"}}
+	mapping.RegisterConverter("BAPIRET2", func(fields map[string]interface{}) (interface{}, error) {
+		return bapiMessage{fields["TYPE"].(string), fields["MESSAGE"].(string)}, nil
+	})
+	c.TypeMapping(mapping)
+
+	date := Date{2024, time.February, 29}
+	r, err := c.Call("STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCDATE": date, "RFCTIME": 90 * time.Minute, "RFCCHAR1": true},
+	})
+	assert.Nil(t, err)
+	echo := r["ECHOSTRUCT"].(map[string]interface{})
+	assert.Equal(t, date, echo["RFCDATE"])
+	assert.Equal(t, 90*time.Minute, echo["RFCTIME"])
+	assert.Equal(t, true, echo["RFCCHAR1"])
+
+	r, err = c.Call("BAPI_USER_GET_DETAIL", map[string]interface{}{"USERNAME": "NOT_EXISTING"})
+	assert.Nil(t, err)
+	assert.Equal(t, "E", r["RETURN"].([]interface{})[0].(bapiMessage).Type)
+	c.Close()
+}
@@ -0,0 +1,418 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+/*
+#include <stdlib.h>
+#include <sapnwrfc.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+//################################################################################
+//# BACKGROUND RFC                                                               #
+//################################################################################
+
+// UnitType of bgRFC unit
+type UnitType string
+
+const (
+	// UnitSynchronous unit is executed once, without queue order ('T' type)
+	UnitSynchronous UnitType = "T"
+	// UnitQueued unit is executed once, in the order of its queues ('Q' type)
+	UnitQueued UnitType = "Q"
+)
+
+// UnitIdentifier identifies the bgRFC unit in the ABAP system
+type UnitIdentifier struct {
+	Type UnitType
+	ID   string
+}
+
+// UnitState of bgRFC unit in the ABAP system
+type UnitState int
+
+const (
+	// UnitNotFound unit is unknown to the ABAP system, not yet submitted or already deleted
+	UnitNotFound UnitState = iota
+	// UnitInProcess unit is being executed
+	UnitInProcess
+	// UnitCommitted unit is executed and committed, not yet confirmed
+	UnitCommitted
+	// UnitRolledBack unit execution failed and was rolled back
+	UnitRolledBack
+	// UnitConfirmed unit is confirmed, the ABAP system has released the unit ID
+	UnitConfirmed
+)
+
+func (state UnitState) String() string {
+	switch state {
+	case UnitNotFound:
+		return "NOT_FOUND"
+	case UnitInProcess:
+		return "IN_PROCESS"
+	case UnitCommitted:
+		return "COMMITTED"
+	case UnitRolledBack:
+		return "ROLLED_BACK"
+	case UnitConfirmed:
+		return "CONFIRMED"
+	}
+	return fmt.Sprintf("UnitState(%d)", int(state))
+}
+
+// UnitStore persists bgRFC unit IDs until confirmed, so that the unit
+// can be resumed with the same ID after a crash, without posting it twice.
+type UnitStore interface {
+	// Save records the state of the unit
+	Save(unit PendingUnit) error
+	// Delete removes the confirmed unit
+	Delete(unitID string) error
+	// Pending returns units not yet confirmed
+	Pending() ([]PendingUnit, error)
+}
+
+// PendingUnit is a bgRFC unit not yet confirmed
+type PendingUnit struct {
+	Unit       UnitIdentifier
+	QueueNames []string
+	State      TIDState
+}
+
+// DefaultUnitStoreFile is used when no UnitStore is set on the connection
+var DefaultUnitStoreFile = "gorfc_unit.json"
+
+var defaultUnitStore = struct {
+	sync.Mutex
+	store UnitStore
+}{}
+
+// FileUnitStore is a UnitStore persisting unit IDs in a JSON file
+type FileUnitStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileUnitStore returns the UnitStore persisting unit IDs in the file at path
+func NewFileUnitStore(path string) *FileUnitStore {
+	return &FileUnitStore{path: path}
+}
+
+func (store *FileUnitStore) load() (units map[string]PendingUnit, err error) {
+	units = make(map[string]PendingUnit)
+	err = readJSONFile(store.path, &units)
+	return
+}
+
+// Save records the state of the unit
+func (store *FileUnitStore) Save(unit PendingUnit) (err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	units, err := store.load()
+	if err != nil {
+		return
+	}
+	units[unit.Unit.ID] = unit
+	return writeJSONFile(store.path, units)
+}
+
+// Delete removes the confirmed unit
+func (store *FileUnitStore) Delete(unitID string) (err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	units, err := store.load()
+	if err != nil {
+		return
+	}
+	if _, ok := units[unitID]; !ok {
+		return
+	}
+	delete(units, unitID)
+	return writeJSONFile(store.path, units)
+}
+
+// Pending returns units not yet confirmed
+func (store *FileUnitStore) Pending() (pending []PendingUnit, err error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	units, err := store.load()
+	if err != nil {
+		return
+	}
+	for _, unit := range units {
+		pending = append(pending, unit)
+	}
+	return
+}
+
+// Unit groups function calls executed exactly once by background RFC
+type Unit struct {
+	conn       *Connection
+	handle     C.RFC_UNIT_HANDLE
+	identifier UnitIdentifier
+	queueNames []string
+	submitted  bool
+}
+
+func (conn *Connection) getUnitStore() UnitStore {
+	if conn.unitStore == nil {
+		defaultUnitStore.Lock()
+		if defaultUnitStore.store == nil {
+			defaultUnitStore.store = NewFileUnitStore(DefaultUnitStoreFile)
+		}
+		defaultUnitStore.Unlock()
+		conn.unitStore = defaultUnitStore.store
+	}
+	return conn.unitStore
+}
+
+// CreateUnit creates a new bgRFC unit with the unit ID assigned by the ABAP system.
+// Without queueNames a synchronous type unit is created, otherwise a queued type unit.
+func (conn *Connection) CreateUnit(queueNames ...string) (unit *Unit, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	var uid C.RFC_UNITID
+
+	if err = conn.checkPinned("CreateUnit"); err != nil {
+		return
+	}
+	if !conn.alive {
+		return nil, goRfcError("CreateUnit() method requires an open connection", nil)
+	}
+
+	rc := C.RfcGetUnitID(conn.handle, &uid[0], &errorInfo)
+	if rc != C.RFC_OK {
+		return nil, rfcError(errorInfo, "Could not get unit ID")
+	}
+	unitID, err := nWrapString((*C.SAP_UC)(&uid[0]), 32, true)
+	if err != nil {
+		return
+	}
+
+	unit, err = conn.createUnit(unitID, queueNames)
+	if err != nil {
+		return
+	}
+
+	err = conn.getUnitStore().Save(PendingUnit{unit.identifier, queueNames, TIDCreated})
+	if err != nil {
+		unit.Destroy()
+		return nil, goRfcError(fmt.Sprintf("Could not save unit ID %v", unitID), err)
+	}
+	return
+}
+
+// ResumeUnit creates the unit with the ID of a pending unit, not confirmed before a crash.
+// The ABAP system executes the unit only once.
+func (conn *Connection) ResumeUnit(unitID string, queueNames ...string) (unit *Unit, err error) {
+	if err = conn.checkPinned("ResumeUnit"); err != nil {
+		return
+	}
+	if !conn.alive {
+		return nil, goRfcError("ResumeUnit() method requires an open connection", nil)
+	}
+	return conn.createUnit(unitID, queueNames)
+}
+
+func (conn *Connection) createUnit(unitID string, goQueueNames []string) (unit *Unit, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	var uid C.RFC_UNITID
+	var unitAttr C.RFC_UNIT_ATTRIBUTES
+	var identifier C.RFC_UNIT_IDENTIFIER
+	var queueNames **C.SAP_UC
+
+	err = fillBuffer(uid[:], unitID)
+	if err != nil {
+		return
+	}
+
+	cQueueNames := make([]*C.SAP_UC, len(goQueueNames))
+	defer func() {
+		for _, queueName := range cQueueNames {
+			C.free(unsafe.Pointer(queueName))
+		}
+	}()
+	for i, goQueueName := range goQueueNames {
+		cQueueNames[i], err = fillString(goQueueName)
+		if err != nil {
+			return
+		}
+	}
+	if len(cQueueNames) > 0 {
+		queueNames = &cQueueNames[0]
+	}
+
+	handle := C.RfcCreateUnit(conn.handle, &uid[0], queueNames, C.uint(len(cQueueNames)), &unitAttr, &identifier, &errorInfo)
+	if handle == nil {
+		return nil, rfcError(errorInfo, "Could not create unit %v", unitID)
+	}
+
+	unitType, err := nWrapString(&identifier.unitType, 1, true)
+	if err != nil {
+		C.RfcDestroyUnit(handle, &errorInfo)
+		return
+	}
+	return &Unit{
+		conn:       conn,
+		handle:     handle,
+		identifier: UnitIdentifier{UnitType(unitType), unitID},
+		queueNames: goQueueNames,
+	}, nil
+}
+
+// Identifier returns the unit type and ID
+func (unit *Unit) Identifier() UnitIdentifier {
+	return unit.identifier
+}
+
+// AddFunction adds the function call with given parameters to the unit.
+func (unit *Unit) AddFunction(goFuncName string, params interface{}) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if unit.submitted {
+		return goRfcError("AddFunction() method requires a not submitted unit", nil)
+	}
+	if err = unit.conn.checkPinned("AddFunction"); err != nil {
+		return
+	}
+
+	_, funcCont, err := unit.conn.createFunction(goFuncName, params)
+	if err != nil {
+		return
+	}
+	defer unit.conn.releaseFunctionDesc()
+	defer C.RfcDestroyFunction(funcCont, nil)
+
+	rc := C.RfcInvokeInUnit(unit.handle, funcCont, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not add function \"%v\" to unit %v", goFuncName, unit.identifier.ID)
+	}
+	return
+}
+
+// Submit sends the unit to the ABAP system, to be executed once.
+// On error, Submit can be repeated while the connection is open. The unit belongs to the connection
+// which created it: when the connection broke, destroy the unit, resume it by ResumeUnit with the
+// same unit ID on an open connection, add the functions again and submit.
+func (unit *Unit) Submit() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if err = unit.conn.checkPinned("Submit"); err != nil {
+		return
+	}
+
+	rc := C.RfcSubmitUnit(unit.handle, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not submit unit %v", unit.identifier.ID)
+	}
+	unit.submitted = true
+
+	err = unit.conn.getUnitStore().Save(PendingUnit{unit.identifier, unit.queueNames, TIDExecuted})
+	if err != nil {
+		return goRfcError(fmt.Sprintf("Could not save unit ID %v", unit.identifier.ID), err)
+	}
+	return
+}
+
+// Confirm confirms the submitted unit and releases it.
+func (unit *Unit) Confirm() (err error) {
+	if !unit.submitted {
+		return goRfcError("Confirm() method requires a submitted unit", nil)
+	}
+	err = unit.conn.confirmUnit(unit.identifier)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if destroyErr := unit.Destroy(); err == nil {
+			err = destroyErr
+		}
+	}()
+	return unit.conn.deleteUnitID(unit.identifier.ID)
+}
+
+// Destroy releases the unit, without confirming it. The unit ID remains pending in the UnitStore.
+func (unit *Unit) Destroy() (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+	if unit.handle == nil {
+		return
+	}
+	rc := C.RfcDestroyUnit(unit.handle, &errorInfo)
+	unit.handle = nil
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not destroy unit %v", unit.identifier.ID)
+	}
+	return
+}
+
+func fillUnitIdentifier(unit UnitIdentifier) (identifier C.RFC_UNIT_IDENTIFIER, err error) {
+	unitType, err := fillString(string(unit.Type))
+	defer C.free(unsafe.Pointer(unitType))
+	if err != nil {
+		return
+	}
+	identifier.unitType = *unitType
+	err = fillBuffer(identifier.unitID[:], unit.ID)
+	return
+}
+
+// ConfirmUnit lets the ABAP system delete the executed unit ID and removes it from the UnitStore.
+func (conn *Connection) ConfirmUnit(unit UnitIdentifier) (err error) {
+	err = conn.confirmUnit(unit)
+	if err != nil {
+		return
+	}
+	return conn.deleteUnitID(unit.ID)
+}
+
+func (conn *Connection) confirmUnit(unit UnitIdentifier) (err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if err = conn.checkPinned("ConfirmUnit"); err != nil {
+		return
+	}
+
+	identifier, err := fillUnitIdentifier(unit)
+	if err != nil {
+		return
+	}
+	rc := C.RfcConfirmUnit(conn.handle, &identifier, &errorInfo)
+	if rc != C.RFC_OK {
+		return rfcError(errorInfo, "Could not confirm unit %v", unit.ID)
+	}
+	return
+}
+
+// deleteUnitID removes the confirmed unit ID from the UnitStore
+func (conn *Connection) deleteUnitID(unitID string) (err error) {
+	err = conn.getUnitStore().Delete(unitID)
+	if err != nil {
+		return goRfcError(fmt.Sprintf("Could not delete unit ID %v", unitID), err)
+	}
+	return
+}
+
+// GetUnitState returns the processing state of the unit in the ABAP system.
+func (conn *Connection) GetUnitState(unit UnitIdentifier) (state UnitState, err error) {
+	var errorInfo C.RFC_ERROR_INFO
+
+	if err = conn.checkPinned("GetUnitState"); err != nil {
+		return
+	}
+	var unitState C.RFC_UNIT_STATE
+
+	identifier, err := fillUnitIdentifier(unit)
+	if err != nil {
+		return
+	}
+	rc := C.RfcGetUnitState(conn.handle, &identifier, &unitState, &errorInfo)
+	if rc != C.RFC_OK {
+		return state, rfcError(errorInfo, "Could not get state of unit %v", unit.ID)
+	}
+	return UnitState(unitState), nil
+}
@@ -0,0 +1,84 @@
+package gorfc
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Background RFC Tests
+//
+
+func TestFileUnitStore(t *testing.T) {
+	fmt.Println("bgRFC: File unit store")
+	path := filepath.Join(t.TempDir(), "unit.json")
+	store := NewFileUnitStore(path)
+
+	unit := PendingUnit{UnitIdentifier{UnitQueued, "UID1"}, []string{"Q1", "Q2"}, TIDCreated}
+	assert.Nil(t, store.Save(unit))
+	assert.Nil(t, store.Save(PendingUnit{UnitIdentifier{UnitSynchronous, "UID2"}, nil, TIDCreated}))
+	assert.Nil(t, store.Delete("UID2"))
+	unit.State = TIDExecuted
+	assert.Nil(t, store.Save(unit))
+
+	// reloaded from file
+	pending, err := NewFileUnitStore(path).Pending()
+	assert.Nil(t, err)
+	assert.Equal(t, []PendingUnit{unit}, pending)
+}
+
+func TestUnitState(t *testing.T) {
+	fmt.Println("bgRFC: Unit state names")
+	assert.Equal(t, "COMMITTED", UnitCommitted.String())
+	assert.Equal(t, "UnitState(9)", UnitState(9).String())
+}
+
+func TestUnitSynchronous(t *testing.T) {
+	fmt.Println("bgRFC: Synchronous unit")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	store := NewFileUnitStore(filepath.Join(t.TempDir(), "unit.json"))
+	c.UnitStore(store)
+
+	u, err := c.CreateUnit()
+	assert.Nil(t, err)
+	assert.Equal(t, UnitSynchronous, u.Identifier().Type)
+	assert.Equal(t, 32, len(u.Identifier().ID))
+
+	params := map[string]interface{}{
+		"TCPICDAT": []map[string]interface{}{
+			{"LINE": "Hello bgRFC"},
+		},
+	}
+	assert.Nil(t, u.AddFunction("STFC_WRITE_TO_TCPIC", params))
+	assert.Nil(t, u.Submit())
+
+	state, err := c.GetUnitState(u.Identifier())
+	assert.Nil(t, err)
+	assert.Equal(t, UnitCommitted, state)
+
+	assert.Nil(t, u.Confirm())
+	pending, _ := store.Pending()
+	assert.Empty(t, pending)
+	c.Close()
+}
+
+func TestUnitQueued(t *testing.T) {
+	fmt.Println("bgRFC: Queued unit")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	c.UnitStore(NewFileUnitStore(filepath.Join(t.TempDir(), "unit.json")))
+
+	u, err := c.CreateUnit("GORFC_Q1", "GORFC_Q2")
+	assert.Nil(t, err)
+	assert.Equal(t, UnitQueued, u.Identifier().Type)
+	assert.Nil(t, u.AddFunction("STFC_WRITE_TO_TCPIC", map[string]interface{}{
+		"TCPICDAT": []map[string]interface{}{{"LINE": "Hello queued bgRFC"}},
+	}))
+	assert.Nil(t, u.Submit())
+	assert.Nil(t, u.Confirm())
+	c.Close()
+}
@@ -0,0 +1,348 @@
+//go:build (linux && cgo) || (amd64 && cgo) || (darwin && cgo)
+// +build linux,cgo amd64,cgo darwin,cgo
+
+package gorfc
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+//################################################################################
+//# PARAMETER VALIDATION                                                         #
+//################################################################################
+//# Parameters are checked against the function description before the call,
+//# reporting all problems at once instead of the first one found when filling.
+
+// ValidationError lists the problems found by FunctionDescription.Validate,
+// each prefixed by the path of the parameter or field, like "IT_ITEMS[3].MATNR"
+type ValidationError struct {
+	Problems []string
+}
+
+func (err ValidationError) Error() string {
+	return strings.Join(err.Problems, "; ")
+}
+
+// Validate checks the parameters passed as map or Go structure against the function description:
+// parameter and field names, missing non-optional importing parameters and the Go types,
+// lengths and value ranges of ABAP variables. All problems are returned in one GoRfcError,
+// with the ValidationError as GoError.
+func (funcDesc FunctionDescription) Validate(params interface{}) error {
+	var problems []string
+	add := func(path string, problem interface{}) {
+		if err, ok := problem.(*GoRfcError); ok {
+			problem = err.Description
+		}
+		problems = append(problems, fmt.Sprintf("%v: %v", path, problem))
+	}
+
+	supplied := make(map[string]bool)
+	err := forEachParameter(params, func(goName string, value interface{}) error {
+		paramDesc, ok := funcDesc.parameter(goName)
+		if !ok {
+			add(goName, "unknown parameter")
+			return nil
+		}
+		supplied[paramDesc.Name] = true
+		validateVariable(add, goName, paramDesc.ParameterType, paramDesc.NucLength, paramDesc.Decimals, paramDesc.TypeDesc, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, paramDesc := range funcDesc.Parameters {
+		if paramDesc.Direction == "RFC_IMPORT" && !paramDesc.Optional && !supplied[paramDesc.Name] {
+			add(paramDesc.Name, "missing non-optional parameter")
+		}
+	}
+
+	if problems != nil {
+		sort.Strings(problems)
+		return goRfcError(fmt.Sprintf("Invalid parameters of \"%v\"", funcDesc.Name), ValidationError{problems})
+	}
+	return nil
+}
+
+// parameter returns the description of the parameter goName
+func (funcDesc FunctionDescription) parameter(goName string) (ParameterDescription, bool) {
+	for _, paramDesc := range funcDesc.Parameters {
+		if paramDesc.Name == goName {
+			return paramDesc, true
+		}
+	}
+	return ParameterDescription{}, false
+}
+
+// field returns the description of the field goName
+func (typeDesc TypeDescription) field(goName string) (FieldDescription, bool) {
+	for _, fieldDesc := range typeDesc.Fields {
+		if fieldDesc.Name == goName {
+			return fieldDesc, true
+		}
+	}
+	return FieldDescription{}, false
+}
+
+func validateVariable(add func(path string, problem interface{}), path string, rfcType string, nucLength uint, decimals uint, typeDesc TypeDescription, value interface{}) {
+	value, ok := indirect(value)
+	if !ok {
+		// nil leaves the ABAP initial value
+		return
+	}
+
+	switch rfcType {
+	case "RFCTYPE_STRUCTURE":
+		validateStructure(add, path, typeDesc, value)
+	case "RFCTYPE_TABLE":
+		lines := reflect.ValueOf(value)
+		if lines.Kind() != reflect.Slice && lines.Kind() != reflect.Array {
+			add(path, fmt.Sprintf("GO %T passed to ABAP TABLE, expected GO array", value))
+			return
+		}
+		for i := 0; i < lines.Len(); i++ {
+			validateStructure(add, fmt.Sprintf("%v[%v]", path, i), typeDesc, lines.Index(i).Interface())
+		}
+	default:
+		if err := checkValue(rfcType, nucLength, decimals, value, true); err != nil {
+			add(path, err)
+		}
+	}
+}
+
+func validateStructure(add func(path string, problem interface{}), path string, typeDesc TypeDescription, value interface{}) {
+	value, ok := indirect(value)
+	if !ok {
+		return
+	}
+	s := reflect.ValueOf(value)
+
+	validateField := func(name string, fieldValue interface{}) {
+		fieldDesc, ok := typeDesc.field(name)
+		if !ok {
+			add(path+"."+name, fmt.Sprintf("unknown field of %v", typeDesc.Name))
+			return
+		}
+		validateVariable(add, path+"."+name, fieldDesc.FieldType, fieldDesc.NucLength, fieldDesc.Decimals, fieldDesc.TypeDesc, fieldValue)
+	}
+
+	if s.Kind() == reflect.Map {
+		if s.Type().Key().Kind() != reflect.String {
+			add(path, "structure passed as map with non-string keys")
+			return
+		}
+		for _, nameValue := range s.MapKeys() {
+			validateField(nameValue.String(), s.MapIndex(nameValue).Interface())
+		}
+	} else if s.Kind() == reflect.Struct && !isScalarStruct(s.Type()) {
+		for _, field := range structFields(s.Type()) {
+			fieldValue, ok := fieldByIndex(s, field.index, false)
+			if !ok || (field.omitEmpty && fieldValue.IsZero()) {
+				continue
+			}
+			validateField(field.name, fieldValue.Interface())
+		}
+	}
+	// lines of tables of variables are checked when filled
+}
+
+// checkValue returns the error if the Go value can't be passed to the ABAP variable of rfcType: wrong Go type,
+// negative NUMC, integer exceeding int64, Decimal exceeding the length and decimals, TIME duration or UTCLONG time
+// out of range. The strict check also returns too long CHAR, NUMC or RAW value, non-digits in NUMC, DATE or TIME,
+// invalid FLOAT or decimal string, integer out of range or decimal string or number exceeding the length and decimals.
+// The value must not be nil or pointer.
+func checkValue(rfcType string, nucLength uint, decimals uint, value interface{}, strict bool) error {
+	v := reflect.ValueOf(value)
+	switch rfcType {
+	case "RFCTYPE_CHAR":
+		switch v.Kind() {
+		case reflect.Bool:
+			return nil
+		case reflect.String:
+			if length := len(utf16.Encode([]rune(v.String()))); strict && length > int(nucLength) {
+				return fmt.Errorf("%v characters exceed CHAR length %v", length, nucLength)
+			}
+			return nil
+		}
+		return typeMismatch(rfcType, value, "string or bool")
+	case "RFCTYPE_STRING":
+		if v.Kind() != reflect.String {
+			return typeMismatch(rfcType, value, "string")
+		}
+	case "RFCTYPE_NUM":
+		s, err := numcValue(value)
+		if err != nil || !strict {
+			return err
+		}
+		if !isDigits(s) {
+			return fmt.Errorf("NUMC value %q has non-digit characters", s)
+		}
+		if len(s) > int(nucLength) {
+			return fmt.Errorf("%v digits exceed NUMC length %v", len(s), nucLength)
+		}
+	case "RFCTYPE_BYTE", "RFCTYPE_XSTRING":
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+			return typeMismatch(rfcType, value, "[]byte")
+		}
+		if strict && rfcType == "RFCTYPE_BYTE" && v.Len() > int(nucLength) {
+			return fmt.Errorf("%v bytes exceed RAW length %v", v.Len(), nucLength)
+		}
+	case "RFCTYPE_FLOAT":
+		if _, ok := numberString(v); ok {
+			return nil
+		}
+		if v.Kind() == reflect.String {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64); strict && err != nil {
+				return fmt.Errorf("invalid FLOAT value %q", v.String())
+			}
+			return nil
+		}
+		if _, ok := value.(Decimal); !ok {
+			return typeMismatch(rfcType, value, "float64, integer, Decimal or string")
+		}
+	case "RFCTYPE_BCD", "RFCTYPE_DECF16", "RFCTYPE_DECF34":
+		// Decimal values are always checked, to reject overflowing amounts before the call
+		d, ok := value.(Decimal)
+		if !ok {
+			s, ok := numberString(v)
+			if !ok && v.Kind() != reflect.String {
+				return typeMismatch(rfcType, value, "Decimal, float64, integer or string")
+			}
+			if !strict {
+				return nil
+			}
+			if !ok {
+				s = v.String()
+			}
+			var err error
+			if d, err = ParseDecimal(s); err != nil {
+				return fmt.Errorf("invalid decimal value %v", value)
+			}
+		}
+		switch rfcType {
+		case "RFCTYPE_BCD":
+			return checkPacked(d, int(nucLength), int(decimals))
+		case "RFCTYPE_DECF16":
+			return checkDecfloat(d, 16)
+		}
+		return checkDecfloat(d, 34)
+	case "RFCTYPE_INT1", "RFCTYPE_INT2", "RFCTYPE_INT", "RFCTYPE_INT8":
+		i, err := integerValue(rfcType, value)
+		if err != nil || !strict {
+			return err
+		}
+		min, max := intRange(rfcType)
+		if i < min || i > max {
+			return fmt.Errorf("value %v out of %v range %v to %v", i, strings.TrimPrefix(rfcType, "RFCTYPE_"), min, max)
+		}
+	case "RFCTYPE_DATE":
+		switch value.(type) {
+		case time.Time, Date:
+			return nil
+		}
+		if v.Kind() != reflect.String {
+			return typeMismatch(rfcType, value, "time.Time, Date or string")
+		}
+		if s := v.String(); strict && s != "" && (len(s) != 8 || !isDigits(s)) {
+			return fmt.Errorf("DATE value %q is not YYYYMMDD", s)
+		}
+	case "RFCTYPE_TIME":
+		switch t := value.(type) {
+		case time.Time:
+			return nil
+		case time.Duration:
+			_, err := abapTime(t)
+			return err
+		}
+		if v.Kind() != reflect.String {
+			return typeMismatch(rfcType, value, "time.Time, time.Duration or string")
+		}
+		if s := v.String(); strict && s != "" && (len(s) != 6 || !isDigits(s)) {
+			return fmt.Errorf("TIME value %q is not HHMMSS", s)
+		}
+	case "RFCTYPE_UTCLONG":
+		if t, ok := value.(time.Time); ok {
+			_, err := formatUTCLong(t)
+			return err
+		}
+		if v.Kind() != reflect.String {
+			return typeMismatch(rfcType, value, "time.Time or string")
+		}
+	}
+	return nil
+}
+
+func typeMismatch(rfcType string, value interface{}, expected string) error {
+	return fmt.Errorf("GO %T passed to ABAP %v, expected GO %v", value, strings.TrimPrefix(rfcType, "RFCTYPE_"), expected)
+}
+
+// numcValue returns the digits of the NUMC value passed as string or non-negative integer
+func numcValue(value interface{}) (string, error) {
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return "", fmt.Errorf("negative value %v passed to NUMC", v.Int())
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+	return "", typeMismatch("RFCTYPE_NUM", value, "string or integer")
+}
+
+// integerValue returns the value of the signed or unsigned Go integer
+func integerValue(rfcType string, value interface{}) (int64, error) {
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("value %v out of %v range", v.Uint(), strings.TrimPrefix(rfcType, "RFCTYPE_"))
+		}
+		return int64(v.Uint()), nil
+	}
+	return 0, typeMismatch(rfcType, value, "integer")
+}
+
+// intRange returns the value range of the ABAP integer type
+func intRange(rfcType string) (min int64, max int64) {
+	switch rfcType {
+	case "RFCTYPE_INT1":
+		return 0, math.MaxUint8
+	case "RFCTYPE_INT2":
+		return math.MinInt16, math.MaxInt16
+	case "RFCTYPE_INT":
+		return math.MinInt32, math.MaxInt32
+	}
+	return math.MinInt64, math.MaxInt64
+}
+
+// numberString returns the Go float or integer as string, false for other types
+func numberString(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true
+	}
+	return "", false
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package gorfc
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Parameter Validation Tests
+//
+
+var rfcTestType = TypeDescription{Name: "RFCTEST", NucLength: 64, UcLength: 112, Fields: []FieldDescription{
+	{Name: "RFCFLOAT", FieldType: "RFCTYPE_FLOAT", NucLength: 8, UcLength: 8},
+	{Name: "RFCCHAR4", FieldType: "RFCTYPE_CHAR", NucLength: 4, NucOffset: 8, UcLength: 8, UcOffset: 8},
+	{Name: "RFCINT1", FieldType: "RFCTYPE_INT1", NucLength: 1, NucOffset: 12, UcLength: 1, UcOffset: 16},
+	{Name: "RFCINT2", FieldType: "RFCTYPE_INT2", NucLength: 2, NucOffset: 14, UcLength: 2, UcOffset: 18},
+	{Name: "RFCINT4", FieldType: "RFCTYPE_INT", NucLength: 4, NucOffset: 16, UcLength: 4, UcOffset: 20},
+	{Name: "RFCHEX3", FieldType: "RFCTYPE_BYTE", NucLength: 3, NucOffset: 20, UcLength: 3, UcOffset: 24},
+	{Name: "RFCDATE", FieldType: "RFCTYPE_DATE", NucLength: 8, NucOffset: 23, UcLength: 16, UcOffset: 28},
+	{Name: "RFCTIME", FieldType: "RFCTYPE_TIME", NucLength: 6, NucOffset: 31, UcLength: 12, UcOffset: 44},
+	{Name: "RFCDEC", FieldType: "RFCTYPE_BCD", NucLength: 8, NucOffset: 37, UcLength: 8, UcOffset: 56, Decimals: 2},
+	{Name: "RFCNUMC", FieldType: "RFCTYPE_NUM", NucLength: 10, NucOffset: 45, UcLength: 20, UcOffset: 64},
+}}
+
+var validatedFunction = FunctionDescription{
+	Name: "STFC_STRUCTURE",
+	Parameters: []ParameterDescription{
+		{Name: "ECHOSTRUCT", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_EXPORT", NucLength: 64, UcLength: 112, TypeDesc: rfcTestType},
+		{Name: "IMPORTSTRUCT", ParameterType: "RFCTYPE_STRUCTURE", Direction: "RFC_IMPORT", NucLength: 64, UcLength: 112, TypeDesc: rfcTestType},
+		{Name: "REQUTEXT", ParameterType: "RFCTYPE_CHAR", Direction: "RFC_IMPORT", NucLength: 10, UcLength: 20, Optional: true},
+		{Name: "COUNTER", ParameterType: "RFCTYPE_INT8", Direction: "RFC_IMPORT", NucLength: 8, UcLength: 8, Optional: true},
+		{Name: "RFCTABLE", ParameterType: "RFCTYPE_TABLE", Direction: "RFC_TABLES", NucLength: 64, UcLength: 112, TypeDesc: rfcTestType},
+	},
+}
+
+type rfcTestLine struct {
+	Float  float64 `rfc:"RFCFLOAT"`
+	Char4  string  `rfc:"RFCCHAR4"`
+	Int1   uint8   `rfc:"RFCINT1,omitempty"`
+	Number uint64  `rfc:"RFCNUMC,omitempty"`
+}
+
+func TestValidate(t *testing.T) {
+	fmt.Println("Validation: Valid parameters")
+	date := Date{2024, time.February, 29}
+	assert.Nil(t, validatedFunction.Validate(map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{
+			"RFCFLOAT": float32(1.5), "RFCCHAR4": "ÄÖÜ€", "RFCINT1": 255, "RFCINT2": int16(-32768), "RFCINT4": uint(math.MaxInt32),
+			"RFCHEX3": []byte{1, 2, 3}, "RFCDATE": date, "RFCTIME": "235959", "RFCDEC": "99999999999.99-", "RFCNUMC": "0000012345",
+		},
+		"REQUTEXT": true,
+		"COUNTER":  int64(math.MinInt64),
+		"RFCTABLE": []rfcTestLine{{Float: 1, Char4: "ABCD", Number: 9999999999}, {}},
+	}))
+	assert.Nil(t, validatedFunction.Validate(&struct {
+		ImportStruct *rfcTestLine
+		RfcTable     []*rfcTestLine
+	}{&rfcTestLine{Char4: "X"}, []*rfcTestLine{nil}}))
+	assert.Nil(t, validatedFunction.Validate(map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCDATE": time.Now(), "RFCTIME": 90 * time.Minute, "RFCDEC": NewDecimal(-1, 2), "RFCNUMC": ""},
+	}))
+}
+
+func TestValidateProblems(t *testing.T) {
+	fmt.Println("Validation: All problems reported")
+	err := validatedFunction.Validate(map[string]interface{}{
+		"IMPORTSTRUCT_": map[string]interface{}{},
+		"REQUTEXT":      "Hello World",
+		"COUNTER":       uint64(math.MaxUint64),
+		"RFCTABLE": []interface{}{
+			map[string]interface{}{"RFCFLOAT": 1, "RFCCHAR4": 1234, "RFCINT1": 256, "RFCINT2": 40000, "RFCINT4": "1"},
+			map[string]interface{}{"RFCHEX3": []byte{1, 2, 3, 4}, "RFCDATE": "2024-02-29", "RFCTIME": 24 * time.Hour},
+			rfcTestLine{Char4: "ABCDE", Int1: 1, Number: 12345678901},
+			map[string]interface{}{"RFCDEC": 1.234, "RFCNUMC": "12A", "MATNR": "X"},
+			map[string]interface{}{"RFCDEC": NewDecimal(1, -13), "RFCNUMC": -1, "RFCINT1": -1},
+		},
+	})
+	assert.Equal(t, "Invalid parameters of \"STFC_STRUCTURE\"", err.(*GoRfcError).Description)
+	assert.Equal(t, []string{
+		"COUNTER: value 18446744073709551615 out of INT8 range",
+		"IMPORTSTRUCT: missing non-optional parameter",
+		"IMPORTSTRUCT_: unknown parameter",
+		"REQUTEXT: 11 characters exceed CHAR length 10",
+		"RFCTABLE[0].RFCCHAR4: GO int passed to ABAP CHAR, expected GO string or bool",
+		"RFCTABLE[0].RFCINT1: value 256 out of INT1 range 0 to 255",
+		"RFCTABLE[0].RFCINT2: value 40000 out of INT2 range -32768 to 32767",
+		"RFCTABLE[0].RFCINT4: GO string passed to ABAP INT, expected GO integer",
+		"RFCTABLE[1].RFCDATE: DATE value \"2024-02-29\" is not YYYYMMDD",
+		"RFCTABLE[1].RFCHEX3: 4 bytes exceed RAW length 3",
+		"RFCTABLE[1].RFCTIME: TIME value 24h0m0s out of range 0s to 23h59m59s",
+		"RFCTABLE[2].RFCCHAR4: 5 characters exceed CHAR length 4",
+		"RFCTABLE[2].RFCNUMC: 11 digits exceed NUMC length 10",
+		"RFCTABLE[3].MATNR: unknown field of RFCTEST",
+		"RFCTABLE[3].RFCDEC: decimal 1.234 has 3 decimals, 2 allowed",
+		"RFCTABLE[3].RFCNUMC: NUMC value \"12A\" has non-digit characters",
+		"RFCTABLE[4].RFCDEC: decimal 10000000000000 has 14 integer digits, 13 allowed",
+		"RFCTABLE[4].RFCINT1: value -1 out of INT1 range 0 to 255",
+		"RFCTABLE[4].RFCNUMC: negative value -1 passed to NUMC",
+	}, err.(*GoRfcError).GoError.(ValidationError).Problems)
+
+	err = validatedFunction.Validate(map[string]interface{}{"IMPORTSTRUCT": "ABC", "RFCTABLE": map[string]interface{}{}})
+	assert.Equal(t, "GORFC error: Invalid parameters of \"STFC_STRUCTURE\" | "+
+		"RFCTABLE: GO map[string]interface {} passed to ABAP TABLE, expected GO array", err.Error())
+
+	err = validatedFunction.Validate([]string{"IMPORTSTRUCT"})
+	assert.Equal(t, "Parameters can only be passed as types map[string]interface{} or go-structures", err.(*RfcError).Description)
+}
+
+func TestCheckValueNotStrict(t *testing.T) {
+	fmt.Println("Validation: Go types and Decimal values checked without strict validation")
+	assert.Nil(t, checkValue("RFCTYPE_CHAR", 4, 0, "ABCDE", false))
+	assert.Nil(t, checkValue("RFCTYPE_FLOAT", 8, 0, 1, false))
+	assert.Nil(t, checkValue("RFCTYPE_INT1", 1, 0, 256, false))
+	assert.Nil(t, checkValue("RFCTYPE_BCD", 8, 2, "1.234", false))
+	assert.Equal(t, "GO int passed to ABAP CHAR, expected GO string or bool", checkValue("RFCTYPE_CHAR", 4, 0, 1234, false).Error())
+	assert.Equal(t, "decimal 10000000000000 has 14 integer digits, 13 allowed",
+		checkValue("RFCTYPE_BCD", 8, 2, NewDecimal(1, -13), false).Error())
+	assert.Equal(t, "decimal 1.234 has 3 decimals, 2 allowed", checkValue("RFCTYPE_BCD", 8, 2, NewDecimal(1234, 3), false).Error())
+}
+
+func TestValidateCall(t *testing.T) {
+	fmt.Println("Validation: Strict validation before call")
+	c, err := ConnectionFromParams(abapSystem())
+	assert.Nil(t, err)
+	c.StrictValidation(true)
+	_, err = c.Call("STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCINT1": 256, "RFCCHAR4": "ABCDE"},
+		"XXX":          "wrongParameter",
+	})
+	assert.Equal(t, []string{
+		"IMPORTSTRUCT.RFCCHAR4: 5 characters exceed CHAR length 4",
+		"IMPORTSTRUCT.RFCINT1: value 256 out of INT1 range 0 to 255",
+		"XXX: unknown parameter",
+	}, err.(*GoRfcError).GoError.(ValidationError).Problems)
+
+	_, err = c.Call("STFC_STRUCTURE", map[string]interface{}{"RFCTABLE": []interface{}{}})
+	assert.Equal(t, []string{
+		"IMPORTSTRUCT: missing non-optional parameter",
+	}, err.(*GoRfcError).GoError.(ValidationError).Problems)
+
+	c.StrictValidation(false)
+	_, err = c.Call("STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCCHAR4": "ABCDE", "RFCFLOAT": 1},
+	})
+	assert.Nil(t, err)
+	_, err = c.Call("STFC_STRUCTURE", map[string]interface{}{
+		"IMPORTSTRUCT": map[string]interface{}{"RFCDATE": 20240229},
+	})
+	assert.Equal(t, "Could not fill IMPORTSTRUCT.RFCDATE", err.(*GoRfcError).Description)
+	assert.Equal(t, "GO int passed to ABAP DATE, expected GO time.Time, Date or string", err.(*GoRfcError).GoError.Error())
+	c.Close()
+}